@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"parameterCheck/dump"
+	"parameterCheck/registry"
+	"parameterCheck/result"
+	"parameterCheck/rules"
+	"parameterCheck/settings"
+	"parameterCheck/sqlquote"
+)
+
+const usage = `Usage: dumpChecker [command] [flags]
+
+Commands:
+  interactive    ask whether to re-import the config, then check (default)
+  import-config  import the config workbooks into the config db
+  check          check the dumps against the imported rules
+  report         summarise the result files in the output directory
+  lint           validate the config workbooks
+  history        show compliance over time from the history db
+
+Run "dumpChecker <command> -h" for the flags of a command.
+
+check and interactive exit with 0 when every dump was checked, 1 when the
+NotMatched share is above -fail-threshold, and 2 when a dump or table
+could not be checked.
+`
+
+// options holds the flags shared by the commands. The embedded Settings
+// are resolved by load once the flags are parsed.
+type options struct {
+	settings.Settings
+
+	settingsFile string
+	paths        settings.Settings
+	driverLimits string
+	force        bool
+	vendors      string
+	techs        string
+}
+
+// register adds the shared flags to fs. Flags override the settings file
+// and the environment only when given.
+func (o *options) register(fs *flag.FlagSet) {
+	defaults := settings.Default()
+	fs.StringVar(&o.settingsFile, "settings", "", "JSON settings file (default $"+settings.EnvFile+")")
+	fs.StringVar(&o.paths.ConfigDir, "config-dir", "", "directory holding the vendor config workbooks (default "+defaults.ConfigDir+")")
+	fs.StringVar(&o.paths.ConfigDB, "config-db", "", "path of the SQLite config db (default "+defaults.ConfigDB+")")
+	fs.StringVar(&o.paths.DumpRoot, "dump-dir", "", "root of the dumps, laid out as <vendor>/<tech> (default "+defaults.DumpRoot+")")
+	fs.StringVar(&o.paths.OutputRoot, "output-dir", "", "root of the result files, laid out as <vendor> (default "+defaults.OutputRoot+")")
+	fs.StringVar(&o.paths.Template, "template", "", "empty Access file results are written to (default "+defaults.Template+")")
+	fs.StringVar(&o.paths.OutputFormat, "format", "", "result format, accdb or sqlite (default "+defaults.OutputFormat+")")
+	fs.StringVar(&o.paths.ReportDir, "report-dir", "", "directory receiving an XLSX compliance report per run (default none)")
+	fs.StringVar(&o.paths.HistoryDB, "history-db", "", "SQLite database every run appends its findings to (default none)")
+	fs.IntVar(&o.paths.Workers, "workers", 0, fmt.Sprintf("number of dumps checked at once (default %d)", defaults.Workers))
+	fs.StringVar(&o.driverLimits, "driver-limits", "", "dumps checked at once per driver, e.g. adodb=1,raml=2 (default adodb=2)")
+	fs.DurationVar((*time.Duration)(&o.paths.FileTimeout), "file-timeout", 0, "time allowed for checking one dump, e.g. 30m (default no limit)")
+	fs.DurationVar((*time.Duration)(&o.paths.QueryTimeout), "query-timeout", 0, "time allowed for checking one table of a dump (default no limit)")
+	fs.Float64Var(&o.paths.FailThreshold, "fail-threshold", 0, "NotMatched percentage of checked rows above which check exits with 1 (default 0)")
+	fs.BoolVar(&o.force, "force", false, "check every dump, even those unchanged since their results were written")
+	fs.StringVar(&o.vendors, "vendor", "", "comma-separated vendors to include (default all)")
+	fs.StringVar(&o.techs, "tech", "", "comma-separated technologies to include (default all)")
+}
+
+// load resolves the settings: defaults, then the settings file, then the
+// environment, then the flags given in fs, numeric ones even when zero.
+func (o *options) load(fs *flag.FlagSet) error {
+	s, err := settings.Load(o.settingsFile)
+	if err != nil {
+		return err
+	}
+	limits, err := settings.ParseLimits(o.driverLimits)
+	if err != nil {
+		return fmt.Errorf("invalid -driver-limits: %w", err)
+	}
+	o.paths.DriverLimits = limits
+	s.Merge(o.paths)
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "workers":
+			s.Workers = o.paths.Workers
+		case "file-timeout":
+			s.FileTimeout = o.paths.FileTimeout
+		case "query-timeout":
+			s.QueryTimeout = o.paths.QueryTimeout
+		case "fail-threshold":
+			s.FailThreshold = o.paths.FailThreshold
+		}
+	})
+	o.Settings = s
+	return nil
+}
+
+// selected reports whether the vendor and technology pass the filters.
+func (o *options) selected(vendor, tech string) bool {
+	return inList(o.vendors, vendor) && inList(o.techs, tech)
+}
+
+// inList reports whether v is in the comma-separated list, which allows
+// everything when empty.
+func inList(list, v string) bool {
+	if strings.TrimSpace(list) == "" {
+		return true
+	}
+	for _, item := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// run executes the command in args and returns the process exit code.
+func run(args []string) int {
+	name, args := args[0], args[1:]
+	opts := &options{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts.register(fs)
+
+	var command func(context.Context, *options, []string) int
+	switch name {
+	case "interactive":
+		command = runInteractive
+	case "import-config":
+		command = runImportConfig
+	case "check":
+		command = runCheck
+	case "report":
+		command = runReport
+	case "lint":
+		command = runLint
+	case "history":
+		h := &historyOptions{}
+		h.register(fs)
+		command = h.run
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := opts.load(fs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	return command(ctx, opts, fs.Args())
+}
+
+// interruptContext returns a context cancelled by the first Ctrl-C or
+// SIGTERM. A second one kills the process as usual.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sig:
+			log.Print("Interrupted, cleaning up (interrupt again to exit now)")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sig)
+	}()
+	return ctx, cancel
+}
+
+func runInteractive(ctx context.Context, opts *options, _ []string) int {
+	ensureOLEDB(opts)
+	summary := start(ctx, opts)
+	if summary == nil {
+		return exitOK
+	}
+	return finish(ctx, opts, summary)
+}
+
+func runImportConfig(_ context.Context, opts *options, _ []string) int {
+	workbooks, err := findWorkbooks(opts.ConfigDir)
+	if err != nil {
+		log.Printf("Failed to read config directory: %v", err)
+		return 1
+	}
+	if len(workbooks) == 0 {
+		log.Printf("No vendor workbooks found in %s", opts.ConfigDir)
+		return 1
+	}
+	if err := importConfig(opts, workbooks); err != nil {
+		log.Print(err)
+		return 1
+	}
+	return 0
+}
+
+func runCheck(ctx context.Context, opts *options, _ []string) int {
+	ensureOLEDB(opts)
+	workbooks, err := findWorkbooks(opts.ConfigDir)
+	if err != nil {
+		log.Printf("Failed to read config directory: %v", err)
+		return exitError
+	}
+	return finish(ctx, opts, process_dump(ctx, opts, workbooks))
+}
+
+// finish prints the summary of a check and returns its exit code. A
+// cancelled run is an error whatever it checked.
+func finish(ctx context.Context, opts *options, summary *runSummary) int {
+	summary.print(os.Stdout, opts.FailThreshold)
+	if ctx.Err() != nil {
+		return exitError
+	}
+	return summary.exitCode(opts.FailThreshold)
+}
+
+// runReport prints the flag counts of every table in the result files of
+// the selected vendors and technologies.
+func runReport(ctx context.Context, opts *options, _ []string) int {
+	failed := false
+	seen := make(map[string]bool)
+	for _, t := range registry.Targets() {
+		dir := opts.OutputDir(t.Vendor, t.Tech)
+		if !opts.selected(t.Vendor, t.Tech) || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		files, err := filepath.Glob(filepath.Join(dir, "*_result.accdb"))
+		if err != nil {
+			log.Printf("Error finding result files in %s: %v", dir, err)
+			failed = true
+			continue
+		}
+		for _, file := range files {
+			if err := reportFile(ctx, file); err != nil {
+				log.Printf("Failed to read %s: %v", file, err)
+				failed = true
+			}
+		}
+
+		db := filepath.Join(dir, result.SQLiteFile)
+		if _, err := os.Stat(db); err == nil {
+			if err := reportSQLite(db); err != nil {
+				log.Printf("Failed to read %s: %v", db, err)
+				failed = true
+			}
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func reportFile(ctx context.Context, path string) error {
+	source, err := dump.Open(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	tables, err := source.Tables()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(path)
+	for _, table := range tables {
+		rows, err := source.Rows(ctx, table)
+		if err != nil {
+			return err
+		}
+		flagCol := -1
+		for i, col := range rows.Columns() {
+			if strings.EqualFold(col, rules.ColFlag) {
+				flagCol = i
+			}
+		}
+		counts := make(map[string]int)
+		total := 0
+		for rows.Next() {
+			total++
+			if flagCol >= 0 {
+				counts[rows.Values()[flagCol]]++
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		printCounts(table, total, counts)
+	}
+	return nil
+}
+
+// reportSQLite prints the flag counts of every dump in a SQLite result
+// database.
+func reportSQLite(path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	files, err := queryStrings(db, fmt.Sprintf("SELECT %s FROM %s ORDER BY 1",
+		sqlquote.SQLite(result.ColFile), sqlquote.SQLite(result.FilesTable)))
+	if err != nil {
+		return err
+	}
+	tables, err := queryStrings(db, "SELECT name FROM sqlite_master WHERE type = 'table' AND name <> ? ORDER BY 1", result.FilesTable)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		fmt.Println(file)
+		for _, table := range tables {
+			query := fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE %s = ? GROUP BY 1", sqlquote.SQLite(rules.ColFlag),
+				sqlquote.SQLite(table), sqlquote.SQLite(result.ColFile))
+			rows, err := db.Query(query, file)
+			if err != nil {
+				return err
+			}
+			counts := make(map[string]int)
+			total := 0
+			for rows.Next() {
+				var flag sql.NullString
+				var n int
+				if err := rows.Scan(&flag, &n); err != nil {
+					rows.Close()
+					return err
+				}
+				counts[flag.String] += n
+				total += n
+			}
+			err = rows.Err()
+			rows.Close()
+			if err != nil {
+				return err
+			}
+			if total > 0 {
+				printCounts(table, total, counts)
+			}
+		}
+	}
+	return nil
+}
+
+// queryStrings returns the first column of a query's rows.
+func queryStrings(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// printCounts prints a table's row count and its counts per flag.
+func printCounts(table string, total int, counts map[string]int) {
+	flags := make([]string, 0, len(counts))
+	for f := range counts {
+		flags = append(flags, f)
+	}
+	sort.Strings(flags)
+	var parts []string
+	for _, f := range flags {
+		parts = append(parts, fmt.Sprintf("%s=%d", f, counts[f]))
+	}
+	fmt.Printf("  %-40s %8d rows  %s\n", table, total, strings.Join(parts, " "))
+}
@@ -0,0 +1,72 @@
+package dump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"parameterCheck/dump/jet"
+)
+
+// jetSource reads .mdb/.accdb files with the native jet package.
+type jetSource struct {
+	db *jet.Database
+}
+
+func openJet(path string) (Source, error) {
+	db, err := jet.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &jetSource{db: db}, nil
+}
+
+func (s *jetSource) Tables() ([]string, error) {
+	return s.db.TableNames(), nil
+}
+
+func (s *jetSource) Rows(_ context.Context, table string) (Rows, error) {
+	t, err := s.db.Table(table)
+	if errors.Is(err, jet.ErrNoTable) {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotFound, table)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		columns[i] = c.Name
+	}
+	return &jetRows{rows: t.Rows(), columns: columns}, nil
+}
+
+func (s *jetSource) Close() error {
+	return s.db.Close()
+}
+
+type jetRows struct {
+	rows    *jet.Rows
+	columns []string
+	values  []string
+}
+
+func (r *jetRows) Columns() []string { return r.columns }
+
+func (r *jetRows) Next() bool {
+	if !r.rows.Next() {
+		return false
+	}
+	raw := r.rows.Values()
+	r.values = make([]string, len(raw))
+	for i, v := range raw {
+		r.values[i] = formatValue(v)
+	}
+	return true
+}
+
+func (r *jetRows) Values() []string { return r.values }
+
+func (r *jetRows) Err() error { return r.rows.Err() }
+
+func (r *jetRows) Close() error { return nil }
@@ -0,0 +1,110 @@
+package dump
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-adodb"
+
+	"parameterCheck/sqlquote"
+)
+
+// adodbSource reads .mdb/.accdb files through the Microsoft ACE OLEDB
+// provider. It only works on Windows with the Access Database Engine.
+type adodbSource struct {
+	db   *sql.DB
+	path string
+}
+
+func openADODB(path string) (Source, error) {
+	db, err := sql.Open("adodb", "Provider=Microsoft.ACE.OLEDB.12.0;Data Source="+path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Access DB %s: %w", path, err)
+	}
+	return &adodbSource{db: db, path: path}, nil
+}
+
+func (s *adodbSource) Tables() ([]string, error) {
+	rows, err := s.db.Query("SELECT Name FROM MSysObjects WHERE Type = 1 AND Flags = 0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables of %s: %w", s.path, err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+func (s *adodbSource) Rows(ctx context.Context, table string) (Rows, error) {
+	name, err := sqlquote.Access(table)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT * FROM "+name)
+	if err != nil {
+		// The provider only reports a missing table through its message.
+		if strings.Contains(strings.ToLower(err.Error()), "cannot find the input table") {
+			return nil, fmt.Errorf("%w: %s", ErrTableNotFound, table)
+		}
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &sqlRows{rows: rows, columns: columns}, nil
+}
+
+func (s *adodbSource) Close() error {
+	return s.db.Close()
+}
+
+// sqlRows adapts *sql.Rows to Rows.
+type sqlRows struct {
+	rows    *sql.Rows
+	columns []string
+	values  []string
+	err     error
+}
+
+func (r *sqlRows) Columns() []string { return r.columns }
+
+func (r *sqlRows) Next() bool {
+	if r.err != nil || !r.rows.Next() {
+		return false
+	}
+	raw := make([]interface{}, len(r.columns))
+	ptrs := make([]interface{}, len(r.columns))
+	for i := range raw {
+		ptrs[i] = &raw[i]
+	}
+	if r.err = r.rows.Scan(ptrs...); r.err != nil {
+		return false
+	}
+	r.values = make([]string, len(raw))
+	for i, v := range raw {
+		r.values[i] = formatValue(v)
+	}
+	return true
+}
+
+func (r *sqlRows) Values() []string { return r.values }
+
+func (r *sqlRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rows.Err()
+}
+
+func (r *sqlRows) Close() error { return r.rows.Close() }
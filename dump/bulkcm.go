@@ -0,0 +1,207 @@
+package dump
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColDN is the column holding the distinguished name of a bulk CM managed
+// object.
+const ColDN = "dn"
+
+// openBulkCM opens a 3GPP bulk CM configuration file (bulkCmConfigDataFile),
+// plain or gzipped, as exported by Ericsson OSS/ENM. Each managed object
+// becomes a row of the table named after its class; vendor specific
+// VsDataContainer objects are named after their vsDataType without the
+// "vsData" prefix ("EUtranCellFDD"). The columns are the dn, one column per
+// enclosing object named after its class and holding its id ("MeContext",
+// "ManagedElement", ...) and the attributes. Struct members are flattened
+// into "<struct>.<member>" columns and repeated values are joined with ";".
+func openBulkCM(ctx context.Context, path string) (Source, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	scan := func(yield func(record) bool) error {
+		return scanBulkCM(path, yield)
+	}
+	return newScanSource(ctx, scan)
+}
+
+// moFrame is a managed object being read. element is its XML element,
+// which differs from class for a VsDataContainer.
+type moFrame struct {
+	element string
+	class   string
+	id      string
+	fields  []field
+}
+
+// scanBulkCM walks the managed objects of the bulk CM file at path. An
+// object is passed to yield once its element closes, after its children.
+func scanBulkCM(path string, yield func(record) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := xmlReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	dec := newXMLDecoder(r)
+
+	var stack []*moFrame
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch name := t.Name.Local; {
+			case name == rootBulkCM || name == "configData":
+			case name == "attributes" && len(stack) > 0:
+				if err := readBulkCMAttributes(dec, stack[len(stack)-1]); err != nil {
+					return fmt.Errorf("failed to parse %s: %w", path, err)
+				}
+			case xmlAttr(t, "id") != "":
+				stack = append(stack, &moFrame{element: name, class: name, id: xmlAttr(t, "id")})
+			default:
+				if err := dec.Skip(); err != nil {
+					return fmt.Errorf("failed to parse %s: %w", path, err)
+				}
+			}
+		case xml.EndElement:
+			if len(stack) == 0 || t.Name.Local != stack[len(stack)-1].element {
+				continue
+			}
+			if !yield(bulkCMRecord(stack)) {
+				return nil
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// bulkCMRecord returns the record of the innermost object of stack. The
+// dn names the objects by their elements, as the export does.
+func bulkCMRecord(stack []*moFrame) record {
+	mo := stack[len(stack)-1]
+	dn := make([]string, len(stack))
+	for i, f := range stack {
+		dn[i] = f.element + "=" + f.id
+	}
+
+	fields := []field{{name: ColDN, value: strings.Join(dn, ",")}}
+	// An inner SubNetwork overrides the outer ones.
+	seen := make(map[string]int)
+	for _, f := range stack {
+		if i, ok := seen[f.class]; ok {
+			fields[i].value = f.id
+			continue
+		}
+		seen[f.class] = len(fields)
+		fields = append(fields, field{name: f.class, value: f.id})
+	}
+	return record{table: mo.class, fields: append(fields, mo.fields...)}
+}
+
+// readBulkCMAttributes reads the attributes element of mo. For a
+// VsDataContainer it also sets the class from the vsDataType.
+func readBulkCMAttributes(dec *xml.Decoder, mo *moFrame) error {
+	var values []field
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			mo.fields = append(mo.fields, joinFields(values)...)
+			return nil
+		case xml.StartElement:
+			name := t.Name.Local
+			switch {
+			case name == "vsDataType":
+				class, err := xmlText(dec)
+				if err != nil {
+					return err
+				}
+				mo.class = strings.TrimPrefix(class, "vsData")
+			case name == "vsDataFormatVersion":
+				if err := dec.Skip(); err != nil {
+					return err
+				}
+			case strings.HasPrefix(name, "vsData"):
+				// The vendor attributes are wrapped in a vsData<Class> element.
+				if values, err = readXMLValue(dec, "", values); err != nil {
+					return err
+				}
+			default:
+				if values, err = readXMLValue(dec, name, values); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// readXMLValue reads the element just opened into fields: its text under
+// name or, when it has child elements, each child under "<name>.<child>".
+func readXMLValue(dec *xml.Decoder, name string, fields []field) ([]field, error) {
+	var text strings.Builder
+	nested := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			nested = true
+			child := t.Name.Local
+			if name != "" {
+				child = name + "." + child
+			}
+			if fields, err = readXMLValue(dec, child, fields); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if !nested && name != "" {
+				fields = append(fields, field{name: name, value: strings.TrimSpace(text.String())})
+			}
+			return fields, nil
+		}
+	}
+}
+
+// joinFields merges fields of the same name, joining their values with
+// ";" in the position of the first one.
+func joinFields(fields []field) []field {
+	index := make(map[string]int)
+	var joined []field
+	for _, f := range fields {
+		if i, ok := index[f.name]; ok {
+			joined[i].value += ";" + f.value
+			continue
+		}
+		index[f.name] = len(joined)
+		joined = append(joined, f)
+	}
+	return joined
+}
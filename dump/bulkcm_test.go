@@ -0,0 +1,79 @@
+package dump
+
+import (
+	"reflect"
+	"testing"
+)
+
+const bulkCMExport = `<?xml version="1.0" encoding="UTF-8"?>
+<bulkCmConfigDataFile xmlns="configData.xsd" xmlns:xn="genericNrm.xsd" xmlns:es="EricssonSpecificAttributes.xsd">
+  <configData dnPrefix="">
+    <xn:SubNetwork id="ONRM_ROOT_MO">
+      <xn:SubNetwork id="LTE">
+        <xn:MeContext id="ERBS1">
+          <xn:ManagedElement id="1">
+            <xn:attributes><xn:userLabel>ERBS1</xn:userLabel></xn:attributes>
+            <xn:VsDataContainer id="1">
+              <xn:attributes>
+                <xn:vsDataType>vsDataENodeBFunction</xn:vsDataType>
+                <xn:vsDataFormatVersion>EricssonSpecificAttributes.17.28</xn:vsDataFormatVersion>
+                <es:vsDataENodeBFunction>
+                  <es:eNBId>101</es:eNBId>
+                </es:vsDataENodeBFunction>
+              </xn:attributes>
+              <xn:VsDataContainer id="C1">
+                <xn:attributes>
+                  <xn:vsDataType>vsDataEUtranCellFDD</xn:vsDataType>
+                  <es:vsDataEUtranCellFDD>
+                    <es:qRxLevMin>-124</es:qRxLevMin>
+                    <es:earfcndl>1850</es:earfcndl>
+                    <es:earfcndl>3050</es:earfcndl>
+                    <es:additionalPlmnList><es:mcc>1</es:mcc><es:mnc>2</es:mnc></es:additionalPlmnList>
+                  </es:vsDataEUtranCellFDD>
+                </xn:attributes>
+              </xn:VsDataContainer>
+            </xn:VsDataContainer>
+          </xn:ManagedElement>
+        </xn:MeContext>
+      </xn:SubNetwork>
+    </xn:SubNetwork>
+  </configData>
+</bulkCmConfigDataFile>
+`
+
+func TestBulkCM(t *testing.T) {
+	const (
+		me   = "SubNetwork=ONRM_ROOT_MO,SubNetwork=LTE,MeContext=ERBS1,ManagedElement=1"
+		enb  = me + ",VsDataContainer=1"
+		cell = enb + ",VsDataContainer=C1"
+	)
+	tests := []struct {
+		table   string
+		columns []string
+		rows    [][]string
+	}{
+		{"ManagedElement",
+			[]string{"dn", "SubNetwork", "MeContext", "ManagedElement", "userLabel"},
+			[][]string{{me, "LTE", "ERBS1", "1", "ERBS1"}}},
+		// A VsDataContainer is named after its vsDataType, in the table and
+		// the columns of the objects below it, but keeps its element in the dn.
+		{"ENodeBFunction",
+			[]string{"dn", "SubNetwork", "MeContext", "ManagedElement", "ENodeBFunction", "eNBId"},
+			[][]string{{enb, "LTE", "ERBS1", "1", "1", "101"}}},
+		{"EUtranCellFDD",
+			[]string{"dn", "SubNetwork", "MeContext", "ManagedElement", "ENodeBFunction", "EUtranCellFDD", "qRxLevMin", "earfcndl", "additionalPlmnList.mcc", "additionalPlmnList.mnc"},
+			[][]string{{cell, "LTE", "ERBS1", "1", "1", "C1", "-124", "1850;3050", "1", "2"}}},
+	}
+	path := writeDump(t, "bulk.xml", bulkCMExport)
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			columns, rows := readTable(t, path, tt.table)
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", rows, tt.rows)
+			}
+		})
+	}
+}
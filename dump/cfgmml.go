@@ -0,0 +1,234 @@
+package dump
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ColNeName is the column holding the NE a CFGMML command was exported
+// from, named as in the converted Access dumps.
+const ColNeName = "NE Name"
+
+// cfgmmlVerbs are the MML commands whose parameters make up the tables.
+var cfgmmlVerbs = map[string]bool{"ADD": true, "SET": true, "MOD": true}
+
+// openCFGMML opens a Huawei MML configuration script. Each ADD, SET or MOD
+// command becomes a row of the table named after the command ("ADD GCELL"),
+// whose columns are the NE name followed by the command's parameters. A
+// table may also be requested by its object alone ("GCELL"), which returns
+// the rows of every command on that object.
+func openCFGMML(ctx context.Context, path string) (Source, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	scan := func(yield func(record) bool) error {
+		return scanCFGMML(path, yield)
+	}
+	return newScanSource(ctx, scan)
+}
+
+// mmlCommand matches the start of an MML command, "VERB OBJECT:".
+var mmlCommand = regexp.MustCompile(`^[A-Za-z]+[ \t]+[A-Za-z0-9_]+[ \t]*:`)
+
+// isCFGMML reports whether the text file at path is an MML script: the
+// first line of its first 64 KiB that is neither blank nor a comment starts
+// an MML command.
+func isCFGMML(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(io.LimitReader(f, 64*1024))
+	sc.Buffer(make([]byte, 64*1024), 64*1024)
+	inComment := false
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if inComment {
+			_, rest, ok := strings.Cut(text, "*/")
+			if !ok {
+				continue
+			}
+			inComment, text = false, strings.TrimSpace(rest)
+		}
+		switch {
+		case text == "" || strings.HasPrefix(text, "//"):
+			continue
+		case strings.HasPrefix(text, "/*"):
+			_, rest, ok := strings.Cut(text[2:], "*/")
+			if !ok {
+				inComment = true
+				continue
+			}
+			if text = strings.TrimSpace(rest); text == "" {
+				continue
+			}
+		}
+		return mmlCommand.MatchString(text), nil
+	}
+	// Only comments, or a first line longer than 64 KiB.
+	return false, nil
+}
+
+// scanCFGMML walks the commands of the script at path. The NE is taken from
+// "NE Name" comments and USE ME commands, and defaults to the file name for
+// scripts exported from a single NE.
+func scanCFGMML(path string, yield func(record) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ne := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var stmt strings.Builder
+	inComment := false
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if inComment {
+			if _, rest, ok := strings.Cut(text, "*/"); ok {
+				inComment = false
+				text = strings.TrimSpace(rest)
+			} else {
+				continue
+			}
+		}
+		if text == "" {
+			continue
+		}
+		if stmt.Len() == 0 {
+			switch {
+			case strings.HasPrefix(text, "//"):
+				if name, ok := commentNE(text[2:]); ok {
+					ne = name
+				}
+				continue
+			case strings.HasPrefix(text, "/*"):
+				if _, _, ok := strings.Cut(text[2:], "*/"); !ok {
+					inComment = true
+				}
+				continue
+			}
+		}
+
+		stmt.WriteString(text)
+		stmt.WriteByte(' ')
+		for {
+			s := stmt.String()
+			end := indexUnquoted(s, ';')
+			if end < 0 {
+				break
+			}
+			stmt.Reset()
+			stmt.WriteString(strings.TrimSpace(s[end+1:]))
+
+			verb, object, params, ok := parseCommand(s[:end])
+			if !ok {
+				continue
+			}
+			if verb == "USE" && (object == "ME" || object == "NE") {
+				for _, p := range params {
+					if n := strings.ToUpper(p.name); n == "MENAME" || n == "NENAME" || n == "NAME" {
+						ne = p.value
+					}
+				}
+				continue
+			}
+			if !cfgmmlVerbs[verb] {
+				continue
+			}
+			fields := append([]field{{name: ColNeName, value: ne}}, params...)
+			rec := record{table: verb + " " + object, aliases: []string{object}, fields: fields}
+			if !yield(rec) {
+				return nil
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
+
+// commentNE returns the NE named by a comment such as "NE Name: X".
+func commentNE(comment string) (string, bool) {
+	key, value, ok := strings.Cut(comment, ":")
+	if !ok {
+		key, value, ok = strings.Cut(comment, "=")
+	}
+	if !ok {
+		return "", false
+	}
+	switch strings.ToUpper(strings.Join(strings.Fields(key), "")) {
+	case "NENAME", "MENAME":
+		return unquote(strings.TrimSpace(value)), true
+	}
+	return "", false
+}
+
+// parseCommand splits an MML command "VERB OBJECT:P1=V1, P2=V2" into its
+// upper-cased verb and object and its parameters.
+func parseCommand(cmd string) (verb, object string, params []field, ok bool) {
+	head, args, _ := strings.Cut(cmd, ":")
+	words := strings.Fields(strings.ToUpper(head))
+	if len(words) < 2 {
+		return "", "", nil, false
+	}
+	verb, object = words[0], strings.Join(words[1:], " ")
+
+	for len(args) > 0 {
+		end := indexUnquoted(args, ',')
+		if end < 0 {
+			end = len(args)
+		}
+		name, value, found := strings.Cut(args[:end], "=")
+		if name = strings.TrimSpace(name); found && name != "" {
+			params = append(params, field{name: name, value: unquote(strings.TrimSpace(value))})
+		}
+		if end == len(args) {
+			break
+		}
+		args = args[end+1:]
+	}
+	return verb, object, params, true
+}
+
+// indexUnquoted returns the index of the first c outside double quotes.
+func indexUnquoted(s string, c byte) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case c:
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package dump
+
+import (
+	"reflect"
+	"testing"
+)
+
+const cfgmmlScript = "\ufeff/* Exported by MML\n   script */\n" + `//NE Name: BSC1
+ADD GCELL: CELLID=0, CELLNAME="C,1", MCC="510";
+SET GCELL: CELLID=0,
+  CELLNAME="A;B";
+LST GCELL:;
+USE ME: MENAME="BSC2";
+ADD GCELL: CELLID=1, CELLNAME="C2", MNC="01";
+`
+
+func TestCFGMML(t *testing.T) {
+	tests := []struct {
+		table   string
+		columns []string
+		rows    [][]string
+	}{
+		// Quoted values keep their commas; the NE changes with USE ME.
+		{"ADD GCELL",
+			[]string{ColNeName, "CELLID", "CELLNAME", "MCC", "MNC"},
+			[][]string{{"BSC1", "0", "C,1", "510", ""}, {"BSC2", "1", "C2", "", "01"}}},
+		// A command may span lines and hold a quoted semicolon.
+		{"SET GCELL",
+			[]string{ColNeName, "CELLID", "CELLNAME"},
+			[][]string{{"BSC1", "0", "A;B"}}},
+		{"GCELL",
+			[]string{ColNeName, "CELLID", "CELLNAME", "MCC", "MNC"},
+			[][]string{{"BSC1", "0", "C,1", "510", ""}, {"BSC1", "0", "A;B", "", ""}, {"BSC2", "1", "C2", "", "01"}}},
+	}
+	path := writeDump(t, "BSC.txt", cfgmmlScript)
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			columns, rows := readTable(t, path, tt.table)
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestCFGMMLFileNameNE(t *testing.T) {
+	path := writeDump(t, "MBTS_1.mml", "ADD CELL: LOCALCELLID=1;\n")
+	_, rows := readTable(t, path, "CELL")
+	if want := [][]string{{"MBTS_1", "1"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
@@ -0,0 +1,90 @@
+package dump
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// openCSV opens a CSV parameter export, such as ZTE's per-MO exports: a
+// single .csv file is one table named after the file, and a .zip archive
+// holds one table per .csv entry. The first line of each file is the
+// header, and the only one: a header spanning several lines would have its
+// other lines read as data, so such exports must be saved as workbooks
+// with merged header cells or flattened first. The delimiter (comma,
+// semicolon or tab) is taken from the header.
+func openCSV(name string) (Source, error) {
+	if !strings.EqualFold(filepath.Ext(name), ".zip") {
+		if _, err := os.Stat(name); err != nil {
+			return nil, err
+		}
+		s := newTabularSource(nil)
+		s.add(tableName(name), func() (lineReader, error) {
+			f, err := os.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			return newCSVLines(f), nil
+		})
+		return s, nil
+	}
+
+	z, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	s := newTabularSource(z)
+	for _, entry := range z.File {
+		if entry.FileInfo().IsDir() || !strings.EqualFold(path.Ext(entry.Name), ".csv") {
+			continue
+		}
+		s.add(tableName(entry.Name), func() (lineReader, error) {
+			rc, err := entry.Open()
+			if err != nil {
+				return nil, err
+			}
+			return newCSVLines(rc), nil
+		})
+	}
+	return s, nil
+}
+
+// tableName returns the file name of p without directory and extension.
+func tableName(p string) string {
+	base := path.Base(filepath.ToSlash(p))
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// csvLines reads the lines of a CSV file.
+type csvLines struct {
+	*csv.Reader
+	closer io.Closer
+}
+
+func newCSVLines(rc io.ReadCloser) *csvLines {
+	br := bufio.NewReader(rc)
+	r := csv.NewReader(br)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if first, _ := br.Peek(br.Size()); len(first) > 0 {
+		if i := bytes.IndexByte(first, '\n'); i >= 0 {
+			first = first[:i]
+		}
+		comma, most := ',', bytes.Count(first, []byte{','})
+		for _, c := range []rune{';', '\t'} {
+			if n := bytes.Count(first, []byte(string(c))); n > most {
+				comma, most = c, n
+			}
+		}
+		r.Comma = comma
+	}
+	return &csvLines{Reader: r, closer: rc}
+}
+
+func (l *csvLines) Close() error { return l.closer.Close() }
@@ -0,0 +1,88 @@
+package dump
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		columns []string
+		rows    [][]string
+	}{
+		{"comma.csv", "\ufeffSubNetwork,ManagedElement,ldn,pci,userLabel,\n1,100,\"EUtranCellFDD=1\",12,\"A, b\",x\n,,,,,\n1,100,EUtranCellFDD=2,13\n",
+			// Unnamed header cells are named as the Access import does,
+			// blank lines are skipped and short lines padded.
+			[]string{"SubNetwork", "ManagedElement", "ldn", "pci", "userLabel", "F6"},
+			[][]string{{"1", "100", "EUtranCellFDD=1", "12", "A, b", "x"}, {"1", "100", "EUtranCellFDD=2", "13", "", ""}}},
+		{"semicolon.csv", "ManagedElement;ldn;nrPci\n100;NRCellDU=1;7,5\n",
+			[]string{"ManagedElement", "ldn", "nrPci"},
+			[][]string{{"100", "NRCellDU=1", "7,5"}}},
+		{"tab.csv", "ManagedElement\tpci\n100\t 12 \n",
+			[]string{"ManagedElement", "pci"},
+			[][]string{{"100", "12"}}},
+		{"header-only.csv", "ManagedElement,pci\n",
+			[]string{"ManagedElement", "pci"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeDump(t, tt.name, tt.content)
+			columns, rows := readTable(t, path, tableName(tt.name))
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestCSVZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zte.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"LTE/EUtranCellFDD.csv": "ldn,pci\nEUtranCellFDD=1,12\n",
+		"NRCellDU.CSV":          "ldn;nrPci\nNRCellDU=1;7\n",
+		"readme.txt":            "not a table\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	src, err := openCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, _ := src.Tables()
+	src.Close()
+	if len(tables) != 2 {
+		t.Errorf("Tables() = %q, want EUtranCellFDD and NRCellDU", tables)
+	}
+	columns, rows := readTable(t, path, "eutrancellfdd")
+	if want := []string{"ldn", "pci"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %q, want %q", columns, want)
+	}
+	if want := [][]string{{"EUtranCellFDD=1", "12"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+	if _, rows := readTable(t, path, "NRCellDU"); !reflect.DeepEqual(rows, [][]string{{"NRCellDU=1", "7"}}) {
+		t.Errorf("NRCellDU rows = %q", rows)
+	}
+}
@@ -0,0 +1,148 @@
+// Package jet reads Microsoft Jet4 (.mdb) and ACE (.accdb) database files
+// directly, without the Access Database Engine, so dumps can be read on any
+// platform. Only reading is supported, and encrypted databases are not.
+package jet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrNoTable is returned by Database.Table for a name missing from the catalog.
+var ErrNoTable = errors.New("table not found")
+
+const (
+	versionOffset = 0x14
+	catalogPage   = 2
+
+	// MSysObjects entries with Type 1 are local tables; these flag bits mark
+	// the system and hidden ones.
+	objectTypeTable   = 1
+	objectFlagsSystem = 0x80000002
+)
+
+// Database is an open Jet4/ACE file.
+type Database struct {
+	f        *os.File
+	pageSize int
+	numPages int64
+	version  byte
+
+	names  []string
+	tables map[string]catalogEntry
+}
+
+type catalogEntry struct {
+	name string
+	page uint32
+}
+
+// Open opens the database at path and loads its table catalog.
+func Open(path string) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := newDatabase(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+func newDatabase(f *os.File) (*Database, error) {
+	header := make([]byte, 0x20)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if header[0] != 0x00 || !strings.HasPrefix(string(header[4:]), "Standard ") {
+		return nil, fmt.Errorf("not a Jet or ACE database")
+	}
+	version := header[versionOffset]
+	if version == 0 {
+		return nil, fmt.Errorf("Jet3 (Access 97) databases are not supported")
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	db := &Database{
+		f:        f,
+		pageSize: 4096,
+		version:  version,
+		tables:   make(map[string]catalogEntry),
+	}
+	db.numPages = stat.Size() / int64(db.pageSize)
+
+	if err := db.loadCatalog(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// loadCatalog reads the user tables from the MSysObjects system table,
+// whose definition always lives on page 2.
+func (db *Database) loadCatalog() error {
+	catalog, err := db.tableAt("MSysObjects", catalogPage)
+	if err != nil {
+		return fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	idCol := catalog.columnIndex("Id")
+	nameCol := catalog.columnIndex("Name")
+	typeCol := catalog.columnIndex("Type")
+	flagsCol := catalog.columnIndex("Flags")
+	if idCol < 0 || nameCol < 0 || typeCol < 0 || flagsCol < 0 {
+		return fmt.Errorf("catalog is missing required columns")
+	}
+
+	rows := catalog.Rows()
+	for rows.Next() {
+		values := rows.Values()
+		objType, _ := values[typeCol].(int64)
+		flags, _ := values[flagsCol].(int64)
+		id, _ := values[idCol].(int64)
+		name, _ := values[nameCol].(string)
+		if objType != objectTypeTable || uint32(flags)&objectFlagsSystem != 0 || name == "" {
+			continue
+		}
+		if strings.HasPrefix(name, "MSys") {
+			continue
+		}
+		db.names = append(db.names, name)
+		db.tables[strings.ToLower(name)] = catalogEntry{name: name, page: uint32(id) & 0x00ffffff}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sort.Strings(db.names)
+	return nil
+}
+
+// TableNames returns the names of the user tables, sorted.
+func (db *Database) TableNames() []string {
+	return append([]string(nil), db.names...)
+}
+
+// Table loads the definition of the named table. Names are matched without
+// regard to case, as Access does.
+func (db *Database) Table(name string) (*Table, error) {
+	entry, ok := db.tables[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoTable, name)
+	}
+	return db.tableAt(entry.name, entry.page)
+}
+
+// Close closes the underlying file.
+func (db *Database) Close() error {
+	return db.f.Close()
+}
@@ -0,0 +1,165 @@
+package jet
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// countRows reads every row of table and returns their number, and the
+// first row formatted by fmtRow.
+func countRows(t *testing.T, db *Database, table string) (int, string) {
+	t.Helper()
+	tbl, err := db.Table(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := tbl.Rows()
+	n, first := 0, ""
+	for rows.Next() {
+		if n == 0 {
+			first = fmtRow(tbl, rows.Values())
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("table %s: %v", table, err)
+	}
+	return n, first
+}
+
+func fmtRow(tbl *Table, values []interface{}) string {
+	var parts []string
+	for i, c := range tbl.Columns {
+		if s, ok := values[i].(string); ok {
+			parts = append(parts, c.Name+"="+s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestReadFiles(t *testing.T) {
+	tests := []struct {
+		path  string
+		table string
+		rows  int
+		first string
+	}{
+		{"../../EMPTY.accdb", "", 0, ""},
+		{"../../output/nokia/NSN_2G_National_JAVA_20250220.mdb_result.accdb", "A_BSC_GPRS", 7,
+			"Parameter=acUlTbfThreshold CurrentValue= ProposedValue= Flag= BSCID=376198"},
+		{"../../output/nokia/NSN_4G_Dump_20250303_JAVA_FL17.mdb_result.accdb", "A_EQM_EQM_APEQM_ALD_RETU", 11409, ""},
+		{"../../output/huawei/HW_MBTS_CFGMML_253_20250220_@1.accdb_result.accdb", "GeranNfreqGroupArfcn", 24272, ""},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			db, err := Open(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			var want []string
+			if tt.table != "" {
+				want = []string{tt.table}
+			}
+			if got := db.TableNames(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("TableNames() = %q, want %q", got, want)
+			}
+			if tt.table == "" {
+				return
+			}
+			n, first := countRows(t, db, tt.table)
+			if n != tt.rows {
+				t.Errorf("table %s has %d rows, want %d", tt.table, n, tt.rows)
+			}
+			if tt.first != "" && first != tt.first {
+				t.Errorf("first row of %s = %q, want %q", tt.table, first, tt.first)
+			}
+		})
+	}
+}
+
+func TestCorruptRowCount(t *testing.T) {
+	src := "../../output/nokia/NSN_2G_National_JAVA_20250220.mdb_result.accdb"
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Find a data page of the table and make it claim more rows than fit.
+	db, err := Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := db.Table("A_BSC_GPRS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages, err := db.usagePages(tbl.usageMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := false
+	for _, pgNum := range pages {
+		off := int(pgNum) * db.pageSize
+		if data[off] == pageTypeData && le32(data[off:], 4) == tbl.page {
+			data[off+dataRowCount], data[off+dataRowCount+1] = 0xff, 0xff
+			corrupted = true
+			break
+		}
+	}
+	db.Close()
+	if !corrupted {
+		t.Fatal("no data page found")
+	}
+
+	path := filepath.Join(t.TempDir(), "corrupt.accdb")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	db, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tbl, err = db.Table("A_BSC_GPRS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := tbl.Rows()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err == nil || !strings.Contains(err.Error(), "more than fit") {
+		t.Errorf("Rows.Err() = %v, want the row count rejected", err)
+	}
+}
+
+func TestRowBounds(t *testing.T) {
+	pg := make([]byte, 64)
+	pg[dataRowCount] = 2
+	pg[dataRowOffsets] = 48   // row 0: 48-64
+	pg[dataRowOffsets+2] = 40 // row 1: 40-48
+	tests := []struct {
+		n          int
+		start, end int
+		err        bool
+	}{
+		{0, 48, 64, false},
+		{1, 40, 48, false},
+		{2, 0, 0, true},
+	}
+	for _, tt := range tests {
+		start, end, _, err := rowBounds(pg, tt.n)
+		if (err != nil) != tt.err || start != tt.start || end != tt.end {
+			t.Errorf("rowBounds(%d) = %d, %d, %v; want %d, %d, error %v", tt.n, start, end, err, tt.start, tt.end, tt.err)
+		}
+	}
+
+	pg[dataRowCount] = 40 // offsets would run past the page
+	if _, _, _, err := rowBounds(pg, 0); err == nil {
+		t.Error("rowBounds accepted a row count larger than the page")
+	}
+}
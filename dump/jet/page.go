@@ -0,0 +1,142 @@
+package jet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	pageTypeData  = 0x01
+	pageTypeTable = 0x02
+	pageTypeUsage = 0x05
+
+	// Row offsets on data pages carry two flags in their top bits.
+	rowDeleted  = 0x8000
+	rowOverflow = 0x4000
+	rowOffset   = 0x1fff
+
+	dataRowCount   = 0x0c
+	dataRowOffsets = 0x0e
+)
+
+// readPage returns page number pg of the database file.
+func (db *Database) readPage(pg uint32) ([]byte, error) {
+	if int64(pg) >= db.numPages {
+		return nil, fmt.Errorf("page %d is beyond the end of the file", pg)
+	}
+	buf := make([]byte, db.pageSize)
+	if _, err := db.f.ReadAt(buf, int64(pg)*int64(db.pageSize)); err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %w", pg, err)
+	}
+	return buf, nil
+}
+
+// rowCount returns the number of rows of a data page, checking that their
+// offset entries fit on the page.
+func rowCount(pg []byte) (int, error) {
+	count := int(le16(pg, dataRowCount))
+	if dataRowOffsets+2*count > len(pg) {
+		return 0, fmt.Errorf("page claims %d rows, more than fit", count)
+	}
+	return count, nil
+}
+
+// rowBounds returns the position of row n on a data page together with the
+// flags stored in its offset entry. Rows are laid out from the end of the
+// page backwards, so a row ends where the previous one starts.
+func rowBounds(pg []byte, n int) (start, end int, flags uint16, err error) {
+	count, err := rowCount(pg)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if n >= count {
+		return 0, 0, 0, fmt.Errorf("row %d not found, page holds %d rows", n, count)
+	}
+	raw := le16(pg, dataRowOffsets+2*n)
+	start = int(raw & rowOffset)
+	end = len(pg)
+	if n > 0 {
+		end = int(le16(pg, dataRowOffsets+2*(n-1)) & rowOffset)
+	}
+	if start < dataRowOffsets+2*count || start > end || end > len(pg) {
+		return 0, 0, 0, fmt.Errorf("row %d has invalid bounds %d-%d", n, start, end)
+	}
+	return start, end, raw &^ rowOffset, nil
+}
+
+// readRow returns the bytes of the row addressed by a row pointer, which is
+// the row number in the low byte and the page number in the upper three.
+func (db *Database) readRow(ptr uint32) ([]byte, error) {
+	pg, err := db.readPage(ptr >> 8)
+	if err != nil {
+		return nil, err
+	}
+	start, end, _, err := rowBounds(pg, int(ptr&0xff))
+	if err != nil {
+		return nil, fmt.Errorf("page %d: %w", ptr>>8, err)
+	}
+	return pg[start:end], nil
+}
+
+// usagePages decodes the usage map stored in the row at ptr and returns the
+// page numbers it marks as in use. Small maps are an inline bitmap starting
+// at a base page, large ones reference separate bitmap pages.
+func (db *Database) usagePages(ptr uint32) ([]uint32, error) {
+	row, err := db.readRow(ptr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage map: %w", err)
+	}
+	if len(row) < 1 {
+		return nil, fmt.Errorf("empty usage map")
+	}
+
+	var pages []uint32
+	collect := func(base uint32, bitmap []byte) {
+		for i, b := range bitmap {
+			for bit := 0; bit < 8; bit++ {
+				if b&(1<<bit) != 0 {
+					pages = append(pages, base+uint32(i*8+bit))
+				}
+			}
+		}
+	}
+
+	switch row[0] {
+	case 0:
+		if len(row) < 5 {
+			return nil, fmt.Errorf("truncated usage map")
+		}
+		collect(le32(row, 1), row[5:])
+	case 1:
+		perPage := uint32(db.pageSize-4) * 8
+		for i := 0; 1+4*i+4 <= len(row); i++ {
+			mapPage := le32(row, 1+4*i)
+			if mapPage == 0 {
+				continue
+			}
+			pg, err := db.readPage(mapPage)
+			if err != nil {
+				return nil, err
+			}
+			if pg[0] != pageTypeUsage {
+				return nil, fmt.Errorf("page %d is not a usage map page", mapPage)
+			}
+			collect(uint32(i)*perPage, pg[4:])
+		}
+	default:
+		return nil, fmt.Errorf("unknown usage map type %d", row[0])
+	}
+	return pages, nil
+}
+
+func le16(b []byte, off int) uint16 {
+	return binary.LittleEndian.Uint16(b[off:])
+}
+
+func le24(b []byte, off int) uint32 {
+	return uint32(b[off]) | uint32(b[off+1])<<8 | uint32(b[off+2])<<16
+}
+
+func le32(b []byte, off int) uint32 {
+	return binary.LittleEndian.Uint32(b[off:])
+}
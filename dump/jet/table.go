@@ -0,0 +1,354 @@
+package jet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Column types stored in Jet4/ACE table definitions.
+const (
+	TypeBool        = 0x01
+	TypeByte        = 0x02
+	TypeInt         = 0x03
+	TypeLong        = 0x04
+	TypeMoney       = 0x05
+	TypeFloat       = 0x06
+	TypeDouble      = 0x07
+	TypeDateTime    = 0x08
+	TypeBinary      = 0x09
+	TypeText        = 0x0a
+	TypeOLE         = 0x0b
+	TypeMemo        = 0x0c
+	TypeGUID        = 0x0f
+	TypeNumeric     = 0x10
+	TypeComplex     = 0x12
+	TypeBigInt      = 0x13
+	TypeExtDateTime = 0x14
+)
+
+// Offsets into a Jet4 table definition (TDEF) and its column entries.
+const (
+	tdefNextPage   = 0x04
+	tdefRowCount   = 0x10
+	tdefNumVarCols = 0x2b
+	tdefNumCols    = 0x2d
+	tdefNumRealIdx = 0x33
+	tdefUsageMap   = 0x37
+	tdefIndexes    = 0x3f
+
+	realIndexSize = 12
+	columnSize    = 25
+
+	colType        = 0
+	colNum         = 5
+	colVarIndex    = 7
+	colScale       = 12
+	colFlags       = 15
+	colFixedOffset = 21
+	colLength      = 23
+
+	colFlagFixed = 0x01
+)
+
+// Column describes one column of a table.
+type Column struct {
+	Name string
+	Type byte
+
+	num         int
+	varIndex    int
+	fixedOffset int
+	length      int
+	scale       int
+	fixed       bool
+}
+
+// Table is the definition of a table, from which its rows can be read.
+type Table struct {
+	Name     string
+	Columns  []Column
+	RowCount int
+
+	db         *Database
+	page       uint32
+	numVarCols int
+	usageMap   uint32
+}
+
+// tableAt parses the table definition starting at page.
+func (db *Database) tableAt(name string, page uint32) (*Table, error) {
+	buf, err := db.readTableDef(page)
+	if err != nil {
+		return nil, fmt.Errorf("table %s: %w", name, err)
+	}
+	if len(buf) < tdefIndexes {
+		return nil, fmt.Errorf("table %s: truncated definition", name)
+	}
+
+	t := &Table{
+		Name:       name,
+		RowCount:   int(le32(buf, tdefRowCount)),
+		db:         db,
+		page:       page,
+		numVarCols: int(le16(buf, tdefNumVarCols)),
+		usageMap:   le32(buf, tdefUsageMap),
+	}
+
+	numCols := int(le16(buf, tdefNumCols))
+	off := tdefIndexes + int(le32(buf, tdefNumRealIdx))*realIndexSize
+	if off+numCols*columnSize > len(buf) {
+		return nil, fmt.Errorf("table %s: truncated column definitions", name)
+	}
+
+	t.Columns = make([]Column, numCols)
+	for i := range t.Columns {
+		e := buf[off+i*columnSize:]
+		t.Columns[i] = Column{
+			Type:        e[colType],
+			num:         int(le16(e, colNum)),
+			varIndex:    int(le16(e, colVarIndex)),
+			fixedOffset: int(le16(e, colFixedOffset)),
+			length:      int(le16(e, colLength)),
+			scale:       int(e[colScale]),
+			fixed:       e[colFlags]&colFlagFixed != 0,
+		}
+	}
+	off += numCols * columnSize
+
+	// Column names follow in the same order, each prefixed by its byte length.
+	for i := range t.Columns {
+		if off+2 > len(buf) {
+			return nil, fmt.Errorf("table %s: truncated column names", name)
+		}
+		n := int(le16(buf, off))
+		off += 2
+		if off+n > len(buf) {
+			return nil, fmt.Errorf("table %s: truncated column names", name)
+		}
+		t.Columns[i].Name = decodeText(buf[off : off+n])
+		off += n
+	}
+
+	sort.SliceStable(t.Columns, func(i, j int) bool {
+		return t.Columns[i].num < t.Columns[j].num
+	})
+	return t, nil
+}
+
+// readTableDef returns the definition starting at page. Definitions spanning
+// several pages are joined, dropping the header of each continuation page.
+func (db *Database) readTableDef(page uint32) ([]byte, error) {
+	pg, err := db.readPage(page)
+	if err != nil {
+		return nil, err
+	}
+	if pg[0] != pageTypeTable {
+		return nil, fmt.Errorf("page %d is not a table definition", page)
+	}
+
+	buf := pg
+	for next, hops := le32(pg, tdefNextPage), int64(0); next != 0; next = le32(pg, tdefNextPage) {
+		if hops++; hops > db.numPages {
+			return nil, fmt.Errorf("table definition at page %d loops", page)
+		}
+		if pg, err = db.readPage(next); err != nil {
+			return nil, err
+		}
+		if pg[0] != pageTypeTable {
+			return nil, fmt.Errorf("page %d is not a table definition", next)
+		}
+		buf = append(buf, pg[8:]...)
+	}
+	return buf, nil
+}
+
+// columnIndex returns the position of the named column, or -1.
+func (t *Table) columnIndex(name string) int {
+	for i, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Rows returns an iterator over the rows of the table.
+func (t *Table) Rows() *Rows {
+	return &Rows{t: t}
+}
+
+// Rows iterates over the rows of a table, one data page at a time. Values
+// are nil for NULL, bool, int64, float32, float64, string, time.Time or
+// []byte depending on the column type.
+type Rows struct {
+	t *Table
+
+	pages   []uint32
+	loaded  bool
+	next    int
+	page    []byte
+	row     int
+	numRows int
+
+	values []interface{}
+	err    error
+}
+
+// Next advances to the next row and reports whether there is one.
+func (r *Rows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	if !r.loaded {
+		r.loaded = true
+		if r.pages, r.err = r.t.db.usagePages(r.t.usageMap); r.err != nil {
+			r.err = fmt.Errorf("table %s: %w", r.t.Name, r.err)
+			return false
+		}
+	}
+
+	for {
+		if r.page == nil || r.row >= r.numRows {
+			if r.next >= len(r.pages) {
+				return false
+			}
+			pgNum := r.pages[r.next]
+			r.next++
+			pg, err := r.t.db.readPage(pgNum)
+			if err != nil {
+				r.err = err
+				return false
+			}
+			// Skip pages the map marks but which belong to something else.
+			if pg[0] != pageTypeData || le32(pg, 4) != r.t.page {
+				r.page = nil
+				continue
+			}
+			numRows, err := rowCount(pg)
+			if err != nil {
+				r.err = fmt.Errorf("table %s: page %d: %w", r.t.Name, pgNum, err)
+				return false
+			}
+			r.page, r.row, r.numRows = pg, 0, numRows
+		}
+
+		n := r.row
+		r.row++
+		start, end, flags, err := rowBounds(r.page, n)
+		if err != nil {
+			r.err = fmt.Errorf("table %s: %w", r.t.Name, err)
+			return false
+		}
+		if flags&rowDeleted != 0 {
+			continue
+		}
+
+		data := r.page[start:end]
+		// An overflow row only holds a pointer to where the row was moved.
+		if flags&rowOverflow != 0 {
+			if len(data) < 4 {
+				r.err = fmt.Errorf("table %s: truncated overflow pointer", r.t.Name)
+				return false
+			}
+			if data, err = r.t.db.readRow(le32(data, 0)); err != nil {
+				r.err = fmt.Errorf("table %s: %w", r.t.Name, err)
+				return false
+			}
+		}
+
+		if r.values, err = r.t.crackRow(data); err != nil {
+			r.err = fmt.Errorf("table %s: %w", r.t.Name, err)
+			return false
+		}
+		return true
+	}
+}
+
+// Values returns the current row, one value per entry of Table.Columns.
+func (r *Rows) Values() []interface{} {
+	return r.values
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// crackRow splits a row into column values. A Jet4 row starts with its
+// column count and the fixed-length data, followed by the variable-length
+// data; it ends with the variable column offsets (stored back to front),
+// their count and the null mask.
+func (t *Table) crackRow(row []byte) ([]interface{}, error) {
+	n := len(row)
+	if n < 2 {
+		return nil, fmt.Errorf("truncated row")
+	}
+	numCols := int(le16(row, 0))
+	maskLen := (numCols + 7) / 8
+	if n < 2+maskLen {
+		return nil, fmt.Errorf("truncated row")
+	}
+	nullMask := row[n-maskLen:]
+
+	var varOffsets []int
+	rowVarCols := 0
+	if t.numVarCols > 0 {
+		pos := n - maskLen - 2
+		if pos < 2 {
+			return nil, fmt.Errorf("truncated row")
+		}
+		rowVarCols = int(le16(row, pos))
+		if n-maskLen-4-2*rowVarCols < 2 {
+			return nil, fmt.Errorf("invalid variable column count %d", rowVarCols)
+		}
+		varOffsets = make([]int, rowVarCols+1)
+		for i := range varOffsets {
+			varOffsets[i] = int(le16(row, n-maskLen-4-2*i))
+		}
+	}
+	rowFixedCols := numCols - rowVarCols
+	fixedFound := 0
+
+	values := make([]interface{}, len(t.Columns))
+	for i, c := range t.Columns {
+		// A set bit in the null mask means the value is present.
+		present := c.num/8 < len(nullMask) && nullMask[c.num/8]&(1<<(c.num%8)) != 0
+
+		var data []byte
+		switch {
+		case c.fixed && fixedFound < rowFixedCols:
+			fixedFound++
+			start := 2 + c.fixedOffset
+			if start+c.length > n {
+				return nil, fmt.Errorf("column %s overruns the row", c.Name)
+			}
+			data = row[start : start+c.length]
+		case !c.fixed && c.varIndex < rowVarCols:
+			start, end := varOffsets[c.varIndex], varOffsets[c.varIndex+1]
+			if start > end || end > n {
+				return nil, fmt.Errorf("column %s has invalid offsets %d-%d", c.Name, start, end)
+			}
+			data = row[start:end]
+		default:
+			// The column was added after this row was written.
+			present = false
+		}
+
+		// Booleans have no data; the null mask bit is the value itself.
+		if c.Type == TypeBool {
+			values[i] = present
+			continue
+		}
+		if !present {
+			continue
+		}
+
+		v, err := t.db.decodeValue(c, data)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", c.Name, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
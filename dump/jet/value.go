@@ -0,0 +1,215 @@
+package jet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Long values (memo and OLE) keep their storage kind in the top bits of the
+// length word.
+const (
+	longValueInline  = 0x80000000
+	longValueOnePage = 0x40000000
+	longValueLength  = 0x3fffffff
+)
+
+// Jet dates count days, with the time as the fraction, from this epoch.
+var dateEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// decodeValue converts the raw bytes of a non-null column value.
+func (db *Database) decodeValue(c Column, data []byte) (interface{}, error) {
+	need := func(n int) error {
+		if len(data) < n {
+			return fmt.Errorf("value needs %d bytes, has %d", n, len(data))
+		}
+		return nil
+	}
+
+	switch c.Type {
+	case TypeByte:
+		if err := need(1); err != nil {
+			return nil, err
+		}
+		return int64(data[0]), nil
+	case TypeInt:
+		if err := need(2); err != nil {
+			return nil, err
+		}
+		return int64(int16(le16(data, 0))), nil
+	case TypeLong, TypeComplex:
+		if err := need(4); err != nil {
+			return nil, err
+		}
+		return int64(int32(le32(data, 0))), nil
+	case TypeBigInt:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		return int64(binary.LittleEndian.Uint64(data)), nil
+	case TypeMoney:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		v := int64(binary.LittleEndian.Uint64(data))
+		n := big.NewInt(v)
+		return formatScaled(n.Abs(n), 4, v < 0), nil
+	case TypeFloat:
+		if err := need(4); err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(le32(data, 0)), nil
+	case TypeDouble:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+	case TypeDateTime:
+		if err := need(8); err != nil {
+			return nil, err
+		}
+		return decodeDate(math.Float64frombits(binary.LittleEndian.Uint64(data))), nil
+	case TypeGUID:
+		if err := need(16); err != nil {
+			return nil, err
+		}
+		return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}",
+			le32(data, 0), le16(data, 4), le16(data, 6), data[8:10], data[10:16]), nil
+	case TypeNumeric:
+		if err := need(17); err != nil {
+			return nil, err
+		}
+		// Four 32-bit little-endian words, most significant word first.
+		n := new(big.Int)
+		for w := 0; w < 4; w++ {
+			n.Lsh(n, 32)
+			n.Or(n, big.NewInt(int64(le32(data, 1+4*w))))
+		}
+		return formatScaled(n, c.scale, data[0]&0x80 != 0), nil
+	case TypeText:
+		return decodeText(data), nil
+	case TypeMemo:
+		b, err := db.readLongValue(data)
+		if err != nil {
+			return nil, err
+		}
+		return decodeText(b), nil
+	case TypeOLE:
+		return db.readLongValue(data)
+	case TypeExtDateTime:
+		return strings.TrimRight(string(data), "\x00 "), nil
+	default:
+		return append([]byte(nil), data...), nil
+	}
+}
+
+// readLongValue returns the content of a memo or OLE field. The 12-byte
+// field holds the length, a row pointer and the data itself when it is
+// stored inline; otherwise the data is one row elsewhere, or a chain of rows
+// each starting with the pointer to the next.
+func (db *Database) readLongValue(data []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("truncated long value header")
+	}
+	raw := le32(data, 0)
+	length := int(raw & longValueLength)
+
+	switch {
+	case raw&longValueInline != 0:
+		if 12+length > len(data) {
+			return nil, fmt.Errorf("inline long value overruns the row")
+		}
+		return append([]byte(nil), data[12:12+length]...), nil
+	case raw&longValueOnePage != 0:
+		row, err := db.readRow(le32(data, 4))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read long value: %w", err)
+		}
+		if length > len(row) {
+			length = len(row)
+		}
+		return append([]byte(nil), row[:length]...), nil
+	default:
+		var out []byte
+		ptr := le32(data, 4)
+		for hops := int64(0); ptr != 0 && len(out) < length; hops++ {
+			if hops > db.numPages {
+				return nil, fmt.Errorf("long value chain loops")
+			}
+			row, err := db.readRow(ptr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read long value: %w", err)
+			}
+			if len(row) < 4 {
+				return nil, fmt.Errorf("truncated long value page")
+			}
+			ptr = le32(row, 0)
+			out = append(out, row[4:]...)
+		}
+		if len(out) > length {
+			out = out[:length]
+		}
+		return out, nil
+	}
+}
+
+// decodeText decodes UCS-2 text. Values starting with 0xFF 0xFE use Jet4
+// "unicode compression": one byte per character, with a zero byte toggling
+// between compressed and two-byte characters.
+func decodeText(b []byte) string {
+	var units []uint16
+	if len(b) >= 2 && b[0] == 0xff && b[1] == 0xfe {
+		compressed := true
+		b = b[2:]
+		for len(b) > 0 {
+			switch {
+			case b[0] == 0:
+				compressed = !compressed
+				b = b[1:]
+			case compressed:
+				units = append(units, uint16(b[0]))
+				b = b[1:]
+			case len(b) >= 2:
+				units = append(units, le16(b, 0))
+				b = b[2:]
+			default:
+				b = nil
+			}
+		}
+	} else {
+		units = make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = le16(b, 2*i)
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeDate converts a Jet date. Negative dates keep the time of day as a
+// positive fraction.
+func decodeDate(v float64) time.Time {
+	days := math.Trunc(v)
+	frac := math.Abs(v - days)
+	t := dateEpoch.AddDate(0, 0, int(days))
+	return t.Add(time.Duration(math.Round(frac*86400)) * time.Second)
+}
+
+// formatScaled renders n / 10^scale as a decimal without trailing zeros.
+func formatScaled(n *big.Int, scale int, negative bool) string {
+	s := n.String()
+	if scale > 0 {
+		if len(s) <= scale {
+			s = strings.Repeat("0", scale-len(s)+1) + s
+		}
+		s = s[:len(s)-scale] + "." + s[len(s)-scale:]
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if negative && s != "0" {
+		s = "-" + s
+	}
+	return s
+}
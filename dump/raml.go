@@ -0,0 +1,213 @@
+package dump
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColDistName is the column holding the distinguished name of a RAML
+// managed object.
+const ColDistName = "distName"
+
+// openRAML opens a Nokia RAML 2.x plan or dump, plain or gzipped. Each
+// managedObject becomes a row of the table named after its class, without
+// the package prefix ("RETU" for "com.nokia.srbts.eqm:RETU"). The columns
+// are the distName, one "<CLASS>ID" column per distName component and the
+// parameters. A simple list is one column of its values joined with ";"; a
+// list of items has a "<list>.<param>" column per item parameter, holding
+// a value per item.
+//
+// Tables may also be requested by the full class or by the name the Access
+// conversion of the dump gives them: "A_" followed by the distName path
+// below PLMN (and below MRBTS for classes with a package, which is put
+// first instead), e.g. "A_EQM_EQM_APEQM_ALD_RETU" or "A_BSC_GPRS".
+func openRAML(ctx context.Context, path string) (Source, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	scan := func(yield func(record) bool) error {
+		return scanRAML(path, yield)
+	}
+	return newScanSource(ctx, scan)
+}
+
+// scanRAML walks the managed objects of the RAML file at path.
+func scanRAML(path string, yield func(record) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := xmlReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	dec := newXMLDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "managedObject" {
+			continue
+		}
+		rec, err := readManagedObject(dec, start)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if !yield(rec) {
+			return nil
+		}
+	}
+}
+
+// readManagedObject reads the managedObject element opened by start.
+func readManagedObject(dec *xml.Decoder, start xml.StartElement) (record, error) {
+	class, distName := xmlAttr(start, "class"), xmlAttr(start, "distName")
+	pkg, table := "", class
+	if i := strings.LastIndex(class, ":"); i >= 0 {
+		pkg, table = class[:i], class[i+1:]
+	}
+
+	rec := record{table: table}
+	if class != table {
+		rec.aliases = append(rec.aliases, class)
+	}
+	rec.fields = append(rec.fields, field{name: ColDistName, value: distName})
+
+	var path []string
+	for _, part := range strings.Split(distName, "/") {
+		name, id, ok := strings.Cut(part, "-")
+		if !ok {
+			continue
+		}
+		rec.fields = append(rec.fields, field{name: strings.ToUpper(name) + "ID", value: id})
+		path = append(path, strings.ToUpper(name))
+	}
+	if alias := accessTableName(pkg, path); alias != "" {
+		rec.aliases = append(rec.aliases, alias)
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return rec, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return rec, nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				value, err := xmlText(dec)
+				if err != nil {
+					return rec, err
+				}
+				rec.fields = append(rec.fields, field{name: xmlAttr(t, "name"), value: value})
+			case "list":
+				fields, err := readList(dec, xmlAttr(t, "name"))
+				if err != nil {
+					return rec, err
+				}
+				rec.fields = append(rec.fields, fields...)
+			default:
+				if err := dec.Skip(); err != nil {
+					return rec, err
+				}
+			}
+		}
+	}
+}
+
+// readList reads a list element into its columns, see openRAML. An item
+// lacking a parameter leaves an empty slot in that parameter's column, so
+// the n-th value of every column belongs to the n-th item.
+func readList(dec *xml.Decoder, name string) ([]field, error) {
+	var values []string
+	var order []string
+	items := make(map[string][]string)
+	// nItems counts the items started so far.
+	nItems := 0
+	pad := func(p string, n int) {
+		for len(items[p]) < n {
+			items[p] = append(items[p], "")
+		}
+	}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Local != "list" {
+				continue
+			}
+			var fields []field
+			if len(values) > 0 || len(order) == 0 {
+				fields = append(fields, field{name: name, value: strings.Join(values, ";")})
+			}
+			for _, p := range order {
+				pad(p, nItems)
+				fields = append(fields, field{name: name + "." + p, value: strings.Join(items[p], ";")})
+			}
+			return fields, nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "item":
+				// The item's parameters are read as they come.
+				nItems++
+			case "p":
+				value, err := xmlText(dec)
+				if err != nil {
+					return nil, err
+				}
+				p := xmlAttr(t, "name")
+				if p == "" {
+					values = append(values, value)
+					continue
+				}
+				if _, ok := items[p]; !ok {
+					order = append(order, p)
+				}
+				pad(p, nItems-1)
+				items[p] = append(items[p], value)
+			default:
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
+
+// accessTableName returns the name the Access conversion gives a table
+// whose distName classes are path.
+func accessTableName(pkg string, path []string) string {
+	if len(path) > 0 && path[0] == "PLMN" {
+		path = path[1:]
+	}
+	if pkg != "" {
+		if len(path) > 0 && path[0] == "MRBTS" {
+			path = path[1:]
+		}
+		path = append([]string{strings.ToUpper(pkg[strings.LastIndex(pkg, ".")+1:])}, path...)
+	}
+	if len(path) == 0 {
+		return ""
+	}
+	return "A_" + strings.Join(path, "_")
+}
@@ -0,0 +1,64 @@
+package dump
+
+import (
+	"reflect"
+	"testing"
+)
+
+const ramlPlan = `<?xml version="1.0" encoding="UTF-8"?>
+<raml version="2.0" xmlns="raml20.xsd">
+  <cmData type="actual">
+    <managedObject class="com.nokia.srbts.eqm:RETU" distName="PLMN-PLMN/MRBTS-1/EQM-1/APEQM-1/ALD-2/RETU-3" version="EQM20A">
+      <p name="angle">40</p>
+      <list name="ranges">
+        <item><p name="lo">1</p><p name="hi">9</p></item>
+        <item><p name="hi">19</p></item>
+        <item><p name="lo">21</p><p name="hi">29</p></item>
+        <item><p name="lo">31</p></item>
+      </list>
+      <list name="bands"><p>B1</p><p>B3</p></list>
+    </managedObject>
+    <managedObject class="GPRS" distName="PLMN-PLMN/BSC-376198/GPRS-1" version="S16">
+      <p name="acUlTbfThreshold">5</p>
+    </managedObject>
+    <managedObject class="GPRS" distName="PLMN-PLMN/BSC-395628/GPRS-1" version="S16">
+      <p name="acUlTbfThreshold">7</p>
+      <p name="extra">x</p>
+    </managedObject>
+  </cmData>
+</raml>
+`
+
+func TestRAML(t *testing.T) {
+	retuColumns := []string{"distName", "PLMNID", "MRBTSID", "EQMID", "APEQMID", "ALDID", "RETUID", "angle", "ranges.lo", "ranges.hi", "bands"}
+	// An item lacking a parameter keeps its slot in that parameter's column.
+	retuRows := [][]string{{"PLMN-PLMN/MRBTS-1/EQM-1/APEQM-1/ALD-2/RETU-3", "PLMN", "1", "1", "1", "2", "3", "40", "1;;21;31", "9;19;29;", "B1;B3"}}
+	gprsColumns := []string{"distName", "PLMNID", "BSCID", "GPRSID", "acUlTbfThreshold", "extra"}
+	gprsRows := [][]string{
+		{"PLMN-PLMN/BSC-376198/GPRS-1", "PLMN", "376198", "1", "5", ""},
+		{"PLMN-PLMN/BSC-395628/GPRS-1", "PLMN", "395628", "1", "7", "x"},
+	}
+	tests := []struct {
+		table   string
+		columns []string
+		rows    [][]string
+	}{
+		{"RETU", retuColumns, retuRows},
+		{"com.nokia.srbts.eqm:RETU", retuColumns, retuRows},
+		{"A_EQM_EQM_APEQM_ALD_RETU", retuColumns, retuRows},
+		{"GPRS", gprsColumns, gprsRows},
+		{"A_BSC_GPRS", gprsColumns, gprsRows},
+	}
+	path := writeDump(t, "plan.xml", ramlPlan)
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			columns, rows := readTable(t, path, tt.table)
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", rows, tt.rows)
+			}
+		})
+	}
+}
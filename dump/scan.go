@@ -0,0 +1,179 @@
+package dump
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// field is a column value of a text dump record.
+type field struct {
+	name  string
+	value string
+}
+
+// record is one object of a text dump, i.e. a row of table. aliases are
+// other names the table may be requested by.
+type record struct {
+	table   string
+	aliases []string
+	fields  []field
+}
+
+// scanner walks a text dump from the start, passing each record to yield
+// until yield returns false.
+type scanner func(yield func(record) bool) error
+
+// scanTable is the layout of a table found while indexing a text dump.
+type scanTable struct {
+	name    string
+	columns []string
+	index   map[string]int
+}
+
+// scanSource serves text dumps that have no table structure of their own.
+// Opening it scans the file once to collect the tables and the union of
+// their columns; reading a table scans the file again, keeping only that
+// table's records, so memory does not grow with the dump.
+type scanSource struct {
+	scan   scanner
+	names  []string
+	tables map[string]*scanTable
+	// aliases maps extra table names to the tables they stand for.
+	aliases map[string][]string
+}
+
+// newScanSource indexes the dump walked by scan, stopping with ctx's error
+// once ctx is done.
+func newScanSource(ctx context.Context, scan scanner) (*scanSource, error) {
+	s := &scanSource{
+		scan:    scan,
+		tables:  make(map[string]*scanTable),
+		aliases: make(map[string][]string),
+	}
+
+	err := scan(func(rec record) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		key := strings.ToLower(rec.table)
+		t, ok := s.tables[key]
+		if !ok {
+			t = &scanTable{name: rec.table, index: make(map[string]int)}
+			s.tables[key] = t
+			s.names = append(s.names, rec.table)
+		}
+		for _, alias := range rec.aliases {
+			a := strings.ToLower(alias)
+			if a != "" && a != key && !slices.Contains(s.aliases[a], key) {
+				s.aliases[a] = append(s.aliases[a], key)
+			}
+		}
+		for _, f := range rec.fields {
+			col := strings.ToLower(f.name)
+			if _, ok := t.index[col]; !ok {
+				t.index[col] = len(t.columns)
+				t.columns = append(t.columns, f.name)
+			}
+		}
+		return true
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *scanSource) Tables() ([]string, error) {
+	return append([]string(nil), s.names...), nil
+}
+
+// Rows returns the records of table. A name that only matches aliases
+// returns the records of all the tables behind it, over the union of their
+// columns.
+func (s *scanSource) Rows(ctx context.Context, table string) (Rows, error) {
+	key := strings.ToLower(table)
+	var keys []string
+	if _, ok := s.tables[key]; ok {
+		keys = []string{key}
+	} else {
+		keys = s.aliases[key]
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotFound, table)
+	}
+
+	r := &scanRows{ctx: ctx, tables: make(map[string]bool), index: make(map[string]int)}
+	for _, k := range keys {
+		r.tables[k] = true
+		for _, col := range s.tables[k].columns {
+			if _, ok := r.index[strings.ToLower(col)]; !ok {
+				r.index[strings.ToLower(col)] = len(r.columns)
+				r.columns = append(r.columns, col)
+			}
+		}
+	}
+
+	seq := func(yield func(record) bool) {
+		if err := s.scan(yield); err != nil {
+			r.err = err
+		}
+	}
+	r.next, r.stop = iter.Pull(seq)
+	return r, nil
+}
+
+func (s *scanSource) Close() error {
+	return nil
+}
+
+// scanRows pulls the records of the selected tables from a fresh scan,
+// stopping with ctx's error once ctx is done.
+type scanRows struct {
+	ctx     context.Context
+	tables  map[string]bool
+	columns []string
+	index   map[string]int
+
+	next   func() (record, bool)
+	stop   func()
+	values []string
+	err    error
+}
+
+func (r *scanRows) Columns() []string { return r.columns }
+
+func (r *scanRows) Next() bool {
+	for {
+		if err := r.ctx.Err(); err != nil {
+			r.err = err
+			return false
+		}
+		rec, ok := r.next()
+		if !ok {
+			return false
+		}
+		if !r.tables[strings.ToLower(rec.table)] {
+			continue
+		}
+		r.values = make([]string, len(r.columns))
+		for _, f := range rec.fields {
+			r.values[r.index[strings.ToLower(f.name)]] = f.value
+		}
+		return true
+	}
+}
+
+func (r *scanRows) Values() []string { return r.values }
+
+func (r *scanRows) Err() error { return r.err }
+
+func (r *scanRows) Close() error {
+	r.stop()
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package dump reads the tables of vendor configuration dumps through a
+// common Source interface, whatever the file format behind it.
+package dump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTableNotFound is returned by Source.Rows for a table the dump lacks.
+var ErrTableNotFound = errors.New("table not found")
+
+// ErrNotDump is returned by ReaderFor for a file whose extension a dump
+// format uses but whose content is not a dump, such as notes in a .txt.
+var ErrNotDump = errors.New("not a dump")
+
+// Source is an opened dump.
+type Source interface {
+	// Tables returns the names of the tables in the dump.
+	Tables() ([]string, error)
+	// Rows returns an iterator over a table. Table names are matched
+	// without regard to case. Readers backed by a database stop the query
+	// when ctx is done.
+	Rows(ctx context.Context, table string) (Rows, error)
+	Close() error
+}
+
+// Rows iterates over the rows of one table. Values are rendered as strings,
+// with NULL as the empty string.
+type Rows interface {
+	Columns() []string
+	Next() bool
+	Values() []string
+	Err() error
+	Close() error
+}
+
+// Reader names for OpenWith.
+const (
+	ReaderJet    = "jet"
+	ReaderADODB  = "adodb"
+	ReaderCFGMML = "cfgmml"
+	ReaderRAML   = "raml"
+	ReaderBulkCM = "bulkcm"
+	ReaderCSV    = "csv"
+	ReaderXLSX   = "xlsx"
+)
+
+// Open opens the dump at path with the native reader for its format.
+// Readers that index the dump on open stop with ctx's error once ctx is
+// done.
+func Open(ctx context.Context, path string) (Source, error) {
+	reader, err := ReaderFor(path)
+	if err != nil {
+		return nil, err
+	}
+	return OpenWith(ctx, reader, path)
+}
+
+// ReaderFor returns the name of the reader Open uses for the dump at path.
+// XML exports are told apart by their root element, and text files are
+// only taken for CFGMML exports when they start with an MML command.
+func ReaderFor(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mdb", ".accdb":
+		return ReaderJet, nil
+	case ".csv", ".zip":
+		return ReaderCSV, nil
+	case ".xlsx":
+		return ReaderXLSX, nil
+	case ".txt", ".mml":
+		return textReaderFor(path)
+	case ".xml":
+		return xmlReaderFor(path)
+	case ".gz":
+		if strings.EqualFold(filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path))), ".xml") {
+			return xmlReaderFor(path)
+		}
+	}
+	return "", fmt.Errorf("unsupported dump format: %s", path)
+}
+
+// OpenWith opens the dump at path with the named reader, or with the one
+// Open picks when reader is empty.
+func OpenWith(ctx context.Context, reader, path string) (Source, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch reader {
+	case "":
+		return Open(ctx, path)
+	case ReaderJet:
+		return openJet(path)
+	case ReaderADODB:
+		return openADODB(path)
+	case ReaderCFGMML:
+		return openCFGMML(ctx, path)
+	case ReaderRAML:
+		return openRAML(ctx, path)
+	case ReaderBulkCM:
+		return openBulkCM(ctx, path)
+	case ReaderCSV:
+		return openCSV(path)
+	case ReaderXLSX:
+		return openXLSX(path)
+	}
+	return nil, fmt.Errorf("unknown dump reader %q", reader)
+}
+
+// textReaderFor returns the reader for a text export.
+func textReaderFor(path string) (string, error) {
+	ok, err := isCFGMML(path)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: no MML command at its start", ErrNotDump)
+	}
+	return ReaderCFGMML, nil
+}
+
+// xmlReaderFor returns the reader for the root element of an XML export.
+func xmlReaderFor(path string) (string, error) {
+	root, err := xmlRoot(path)
+	if err != nil {
+		return "", err
+	}
+	switch root {
+	case rootRAML:
+		return ReaderRAML, nil
+	case rootBulkCM:
+		return ReaderBulkCM, nil
+	}
+	return "", fmt.Errorf("unsupported XML dump %s: root element %s", path, root)
+}
+
+// formatValue renders a column value the way Access' CSTR would.
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return fmt.Sprintf("%X", x)
+	case bool:
+		if x {
+			return "True"
+		}
+		return "False"
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 {
+			return x.Format("2006-01-02")
+		}
+		return x.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
@@ -0,0 +1,73 @@
+package dump
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeDump writes content to a file named name in a temporary directory
+// and returns its path.
+func writeDump(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readTable opens the dump at path and returns the columns and rows of
+// table.
+func readTable(t *testing.T, path, table string) ([]string, [][]string) {
+	t.Helper()
+	ctx := context.Background()
+	src, err := Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	rows, err := src.Rows(ctx, table)
+	if err != nil {
+		t.Fatalf("table %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	var values [][]string
+	for rows.Next() {
+		values = append(values, append([]string(nil), rows.Values()...))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("table %s: %v", table, err)
+	}
+	return rows.Columns(), values
+}
+
+func TestReaderForText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // empty for ErrNotDump
+	}{
+		{"cmd.txt", "ADD GCELL: CELLID=0;\n", ReaderCFGMML},
+		{"bom.txt", "\ufeffSET BSCBASIC: MCC=\"510\";\n", ReaderCFGMML},
+		{"comments.mml", "\n// NE Name: BSC1\n/* multi\n line */\nMOD GCELL:CELLID=1;\n", ReaderCFGMML},
+		{"notes.txt", "Site survey notes: tilt to be checked\n", ""},
+		{"list.txt", "CELLID,CELLNAME\n1,C1\n", ""},
+		{"comment-only.txt", "// nothing else\n", ""},
+		{"empty.txt", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := ReaderFor(writeDump(t, tt.name, tt.content))
+			switch {
+			case tt.want == "" && !errors.Is(err, ErrNotDump):
+				t.Errorf("ReaderFor = %q, %v; want ErrNotDump", reader, err)
+			case tt.want != "" && (err != nil || reader != tt.want):
+				t.Errorf("ReaderFor = %q, %v; want %q", reader, err, tt.want)
+			}
+		})
+	}
+}
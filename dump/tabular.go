@@ -0,0 +1,122 @@
+package dump
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// lineReader reads the lines of a table given as a header line followed by
+// data lines, such as a CSV file or a worksheet. Read returns io.EOF after
+// the last line.
+type lineReader interface {
+	Read() ([]string, error)
+	Close() error
+}
+
+// tabularSource serves dumps made of header-row tables, one per file or
+// sheet. open is called for every Rows, so tables are streamed.
+type tabularSource struct {
+	names  []string
+	open   map[string]func() (lineReader, error)
+	closer io.Closer
+}
+
+func newTabularSource(closer io.Closer) *tabularSource {
+	return &tabularSource{open: make(map[string]func() (lineReader, error)), closer: closer}
+}
+
+// add registers a table, ignoring a second table of the same name.
+func (s *tabularSource) add(name string, open func() (lineReader, error)) {
+	key := strings.ToLower(name)
+	if _, ok := s.open[key]; ok {
+		return
+	}
+	s.names = append(s.names, name)
+	s.open[key] = open
+}
+
+func (s *tabularSource) Tables() ([]string, error) {
+	return append([]string(nil), s.names...), nil
+}
+
+// Rows reads the header line of table. Unnamed header cells get the F1,
+// F2, ... names the Access import gives them.
+func (s *tabularSource) Rows(_ context.Context, table string) (Rows, error) {
+	open, ok := s.open[strings.ToLower(table)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotFound, table)
+	}
+	lines, err := open()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := lines.Read()
+	if err == io.EOF {
+		header, err = nil, nil
+	}
+	if err != nil {
+		lines.Close()
+		return nil, fmt.Errorf("failed to read header of %s: %w", table, err)
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if name == "" {
+			name = fmt.Sprintf("F%d", i+1)
+		}
+		columns[i] = name
+	}
+	return &tabularRows{lines: lines, columns: columns}, nil
+}
+
+func (s *tabularSource) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// tabularRows returns the data lines of a table, one value per column.
+// Lines without any value are skipped.
+type tabularRows struct {
+	lines   lineReader
+	columns []string
+	values  []string
+	err     error
+}
+
+func (r *tabularRows) Columns() []string { return r.columns }
+
+func (r *tabularRows) Next() bool {
+	for r.err == nil {
+		line, err := r.lines.Read()
+		if err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			return false
+		}
+		values := make([]string, len(r.columns))
+		empty := true
+		for i := range values {
+			if i < len(line) {
+				values[i] = strings.TrimSpace(line[i])
+				empty = empty && values[i] == ""
+			}
+		}
+		if !empty {
+			r.values = values
+			return true
+		}
+	}
+	return false
+}
+
+func (r *tabularRows) Values() []string { return r.values }
+
+func (r *tabularRows) Err() error { return r.err }
+
+func (r *tabularRows) Close() error { return r.lines.Close() }
@@ -0,0 +1,168 @@
+package dump
+
+import (
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// openXLSX opens a workbook parameter export, such as ZTE's, with one
+// table per sheet named after the sheet. The first row of each sheet is
+// the header, unless merged cells make the header span several rows: a
+// cell merged down or across its row pulls the rows below into the header.
+// The column names then join the header cells above each column with ".",
+// outer first ("Cell.pci"), a cell merged down counting once.
+func openXLSX(path string) (Source, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := newTabularSource(f)
+	for _, sheet := range f.GetSheetList() {
+		s.add(sheet, func() (lineReader, error) {
+			merged, depth, err := headerMerges(f, sheet)
+			if err != nil {
+				return nil, err
+			}
+			rows, err := f.Rows(sheet)
+			if err != nil {
+				return nil, err
+			}
+			return &sheetLines{rows: rows, merged: merged, depth: depth}, nil
+		})
+	}
+	return s, nil
+}
+
+// cellRange is a merged range of cells, by 1-based column and row.
+type cellRange struct {
+	col1, row1, col2, row2 int
+	value                  string
+}
+
+// headerMerges returns the merged ranges of sheet that make up its header,
+// and the number of rows of the header.
+func headerMerges(f *excelize.File, sheet string) ([]cellRange, int, error) {
+	cells, err := f.GetMergeCells(sheet)
+	if err != nil {
+		return nil, 0, err
+	}
+	var ranges []cellRange
+	for _, mc := range cells {
+		col1, row1, err := excelize.CellNameToCoordinates(mc.GetStartAxis())
+		if err != nil {
+			return nil, 0, err
+		}
+		col2, row2, err := excelize.CellNameToCoordinates(mc.GetEndAxis())
+		if err != nil {
+			return nil, 0, err
+		}
+		ranges = append(ranges, cellRange{col1, row1, col2, row2, mc.GetCellValue()})
+	}
+
+	// Grow the header until no merged range starting in it reaches past it.
+	var header []cellRange
+	depth := 1
+	for grown := true; grown; {
+		grown, header = false, header[:0]
+		for _, r := range ranges {
+			if r.row1 > depth {
+				continue
+			}
+			header = append(header, r)
+			end := r.row2
+			if r.col2 > r.col1 {
+				end++ // a group title has its members below
+			}
+			if end > depth {
+				depth, grown = end, true
+			}
+		}
+	}
+	return header, depth, nil
+}
+
+// sheetLines streams the rows of a sheet. The first Read returns the
+// header, combined from the rows the merged header ranges span.
+type sheetLines struct {
+	rows   *excelize.Rows
+	merged []cellRange
+	depth  int
+	read   bool
+}
+
+func (l *sheetLines) Read() ([]string, error) {
+	if !l.read {
+		l.read = true
+		if len(l.merged) > 0 {
+			return l.header()
+		}
+	}
+	return l.next()
+}
+
+func (l *sheetLines) next() ([]string, error) {
+	if !l.rows.Next() {
+		if err := l.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return l.rows.Columns(excelize.Options{RawCellValue: true})
+}
+
+// header reads the header rows and names each column after the cells
+// above it.
+func (l *sheetLines) header() ([]string, error) {
+	depth, width := l.depth, 0
+	for _, r := range l.merged {
+		width = max(width, r.col2)
+	}
+	grid := make([][]string, depth)
+	for i := range grid {
+		line, err := l.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		grid[i] = line
+		width = max(width, len(line))
+	}
+	for i := range grid {
+		grid[i] = append(grid[i], make([]string, width-len(grid[i]))...)
+	}
+	// part is false for the cells merged into the one above, which add no
+	// part to the name.
+	part := make([][]bool, depth)
+	for i := range part {
+		part[i] = make([]bool, width)
+		for j := range part[i] {
+			part[i][j] = true
+		}
+	}
+	for _, r := range l.merged {
+		for row := r.row1; row <= r.row2 && row <= depth; row++ {
+			for col := r.col1; col <= r.col2 && col <= width; col++ {
+				grid[row-1][col-1] = r.value
+				part[row-1][col-1] = row == r.row1
+			}
+		}
+	}
+
+	names := make([]string, width)
+	for col := range names {
+		var parts []string
+		for row := range grid {
+			if v := strings.TrimSpace(grid[row][col]); v != "" && part[row][col] {
+				parts = append(parts, v)
+			}
+		}
+		names[col] = strings.Join(parts, ".")
+	}
+	return names, nil
+}
+
+func (l *sheetLines) Close() error { return l.rows.Close() }
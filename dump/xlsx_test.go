@@ -0,0 +1,83 @@
+package dump
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// writeWorkbook saves a workbook of one sheet holding rows from A1, with
+// the given cell ranges merged, and returns its path.
+func writeWorkbook(t *testing.T, sheet string, rows [][]interface{}, merges [][2]string) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, m := range merges {
+		if err := f.MergeCell(sheet, m[0], m[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestXLSX(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]interface{}
+		merges  [][2]string
+		columns []string
+		values  [][]string
+	}{
+		{"single header",
+			[][]interface{}{{"ldn", "pci", nil, "userLabel"}, {"EUtranCellFDD=1", 12, nil, "A"}, {}, {"EUtranCellFDD=2", 13}},
+			nil,
+			[]string{"ldn", "pci", "F3", "userLabel"},
+			[][]string{{"EUtranCellFDD=1", "12", "", "A"}, {"EUtranCellFDD=2", "13", "", ""}}},
+		{"merged header",
+			[][]interface{}{
+				{"ldn", "Frequency", nil, "pci"},
+				{nil, "earfcnDl", "earfcnUl", nil},
+				{"EUtranCellFDD=1", 1850, 19850, 12},
+			},
+			[][2]string{{"A1", "A2"}, {"B1", "C1"}, {"D1", "D2"}},
+			[]string{"ldn", "Frequency.earfcnDl", "Frequency.earfcnUl", "pci"},
+			[][]string{{"EUtranCellFDD=1", "1850", "19850", "12"}}},
+		{"nested groups",
+			[][]interface{}{
+				{"ldn", "Cell", nil, nil},
+				{nil, "Frequency", nil, "pci"},
+				{nil, "dl", "ul", nil},
+				{"EUtranCellFDD=1", 1850, 19850, 12},
+			},
+			[][2]string{{"A1", "A3"}, {"B1", "D1"}, {"B2", "C2"}, {"D2", "D3"}},
+			[]string{"ldn", "Cell.Frequency.dl", "Cell.Frequency.ul", "Cell.pci"},
+			[][]string{{"EUtranCellFDD=1", "1850", "19850", "12"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeWorkbook(t, "EUtranCellFDD", tt.rows, tt.merges)
+			columns, values := readTable(t, path, "EUtranCellFDD")
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("rows = %q, want %q", values, tt.values)
+			}
+		})
+	}
+}
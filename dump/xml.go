@@ -0,0 +1,101 @@
+package dump
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// XML exports are recognised by their root element.
+const (
+	rootRAML   = "raml"
+	rootBulkCM = "bulkCmConfigDataFile"
+)
+
+// xmlReader returns the content of an XML export, decompressing it when
+// gzipped. The returned reader must be closed when it is an io.Closer.
+func xmlReader(f io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// newXMLDecoder returns a decoder converting the encoding an export
+// declares, such as ISO-8859-1 or windows-1252, to UTF-8.
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(label) {
+		case "utf-8", "utf8", "us-ascii", "ascii":
+			return input, nil
+		}
+		enc, err := htmlindex.Get(label)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported encoding %q", label)
+		}
+		return enc.NewDecoder().Reader(input), nil
+	}
+	return dec
+}
+
+// xmlRoot returns the name of the root element of the XML export at path.
+func xmlRoot(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	r, err := xmlReader(f)
+	if err != nil {
+		return "", err
+	}
+	dec := newXMLDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// xmlText returns the character data of the element just opened.
+func xmlText(dec *xml.Decoder) (string, error) {
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			if err := dec.Skip(); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
+
+func xmlAttr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
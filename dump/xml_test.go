@@ -0,0 +1,25 @@
+package dump
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestXMLEncoding(t *testing.T) {
+	const object = `<raml version="2.0"><cmData><managedObject class="BTS" distName="PLMN-PLMN/BSC-1/BCF-2/BTS-3"><p name="name">Caf` + "\xe9" + `</p></managedObject></cmData></raml>`
+	for _, label := range []string{"ISO-8859-1", "windows-1252", "latin1"} {
+		t.Run(label, func(t *testing.T) {
+			path := writeDump(t, "plan.xml", `<?xml version="1.0" encoding="`+label+`"?>`+object)
+			_, rows := readTable(t, path, "BTS")
+			if want := [][]string{{"PLMN-PLMN/BSC-1/BCF-2/BTS-3", "PLMN", "1", "2", "3", "Café"}}; !reflect.DeepEqual(rows, want) {
+				t.Errorf("rows = %q, want %q", rows, want)
+			}
+		})
+	}
+
+	path := writeDump(t, "plan.xml", `<?xml version="1.0" encoding="x-unknown"?>`+object)
+	if _, err := Open(context.Background(), path); err == nil {
+		t.Error("Open accepted an unknown encoding")
+	}
+}
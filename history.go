@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"parameterCheck/result"
+)
+
+// byRegion groups the history by the regions of the settings, which
+// Trend leaves to the caller.
+const byRegion = "region"
+
+// historyOptions are the flags of the history command.
+type historyOptions struct {
+	by     string
+	period string
+	param  string
+	site   string
+	region string
+	table  string
+	since  string
+}
+
+func (h *historyOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&h.by, "by", result.ByParameter, "group by parameter, site, region or table")
+	fs.StringVar(&h.period, "period", result.PerDay, "period of the dump dates, day or month")
+	fs.StringVar(&h.param, "param", "", "only this parameter")
+	fs.StringVar(&h.site, "site", "", "only this site")
+	fs.StringVar(&h.region, "region", "", "only the sites of this region")
+	fs.StringVar(&h.table, "table", "", "only this table")
+	fs.StringVar(&h.since, "since", "", "only dumps dated from this day on, as YYYY-MM-DD")
+}
+
+// run prints the Match and NotMatched counts and the compliance of every
+// group per period, from the history db.
+func (h *historyOptions) run(_ context.Context, opts *options, _ []string) int {
+	if opts.HistoryDB == "" {
+		fmt.Fprintln(os.Stderr, "No history db configured (set historyDb or -history-db)")
+		return 2
+	}
+	filter, err := h.filter(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	points, err := h.trend(opts, filter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Period\t%s\tMatch\tNotMatched\tTotal\tCompliance\n", strings.ToUpper(h.by[:1])+h.by[1:])
+	for _, p := range points {
+		compliance := "-"
+		if checked := p.Match + p.NotMatched; checked > 0 {
+			compliance = fmt.Sprintf("%.1f%%", 100*float64(p.Match)/float64(checked))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\n", p.Period, p.Group, p.Match, p.NotMatched, p.Total, compliance)
+	}
+	tw.Flush()
+	return 0
+}
+
+// filter returns the findings the flags of h and opts select.
+func (h *historyOptions) filter(opts *options) (result.TrendFilter, error) {
+	filter := result.TrendFilter{
+		Vendors:   splitList(opts.vendors),
+		Techs:     splitList(opts.techs),
+		Site:      h.site,
+		Table:     h.table,
+		Parameter: h.param,
+	}
+	if h.region != "" {
+		filter.SiteFilter = func(site string) bool {
+			return strings.EqualFold(regionOf(opts.Regions, site), h.region)
+		}
+	}
+	if h.since != "" {
+		since, err := time.ParseInLocation(time.DateOnly, h.since, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("invalid -since: %v", err)
+		}
+		filter.Since = since
+	}
+	return filter, nil
+}
+
+// trend returns the trend of the history db grouped as h says, summing the
+// sites per region for -by region.
+func (h *historyOptions) trend(opts *options, filter result.TrendFilter) ([]result.TrendPoint, error) {
+	if h.by != byRegion {
+		return result.Trend(opts.HistoryDB, h.by, h.period, filter)
+	}
+	points, err := result.Trend(opts.HistoryDB, result.BySite, h.period, filter)
+	if err != nil {
+		return nil, err
+	}
+	return sumRegions(points, opts.Regions), nil
+}
+
+// sumRegions sums per-site points per region and period.
+func sumRegions(points []result.TrendPoint, regions map[string]string) []result.TrendPoint {
+	sums := make(map[[2]string]*result.TrendPoint)
+	for _, p := range points {
+		key := [2]string{regionOf(regions, p.Group), p.Period}
+		sum, ok := sums[key]
+		if !ok {
+			sum = &result.TrendPoint{Period: key[1], Group: key[0]}
+			sums[key] = sum
+		}
+		sum.Match += p.Match
+		sum.NotMatched += p.NotMatched
+		sum.Total += p.Total
+	}
+	out := make([]result.TrendPoint, 0, len(sums))
+	for _, sum := range sums {
+		out = append(out, *sum)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Group != out[j].Group {
+			return out[i].Group < out[j].Group
+		}
+		return out[i].Period < out[j].Period
+	})
+	return out
+}
+
+// regionOf returns the region of the first pattern, in sorted order, that
+// matches site without regard to case, or "" when none does.
+func regionOf(regions map[string]string, site string) string {
+	patterns := make([]string, 0, len(regions))
+	for p := range regions {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(site)); ok {
+			return regions[p]
+		}
+	}
+	return ""
+}
+
+// splitList returns the items of a comma-separated list.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
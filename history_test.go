@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"parameterCheck/result"
+	"parameterCheck/rules"
+	"parameterCheck/settings"
+)
+
+func TestRegionOf(t *testing.T) {
+	regions := map[string]string{"JKT*": "Jakarta", "BDG*": "Bandung", "JKT9*": "Jakarta East"}
+	tests := []struct {
+		site, want string
+	}{
+		{"JKT001", "Jakarta"},
+		{"jkt002", "Jakarta"},
+		{"BDG010", "Bandung"},
+		{"JKT901", "Jakarta"}, // "JKT*" sorts before "JKT9*"
+		{"SBY001", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := regionOf(regions, tt.site); got != tt.want {
+			t.Errorf("regionOf(%q) = %q, want %q", tt.site, got, tt.want)
+		}
+	}
+}
+
+// writeTestHistory records one dump of 2025-02-20 with a finding per site
+// and returns the path of the history.
+func writeTestHistory(t *testing.T, sites map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.db")
+	h, err := result.OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := filepath.Join(dir, "BSC_20250220.txt")
+	if err := os.WriteFile(dump, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := h.Target("Huawei", "2G").Open(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.BeginTable("GCELL", append([]string{"NE Name"}, rules.ResultColumns...)); err != nil {
+		t.Fatal(err)
+	}
+	for site, flag := range sites {
+		if err := f.Write(rules.Record{site, "P1", "1", "1", flag}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHistoryRegions(t *testing.T) {
+	path := writeTestHistory(t, map[string]string{
+		"JKT001": rules.FlagMatch,
+		"jkt002": rules.FlagNotMatched,
+		"BDG010": rules.FlagMatch,
+		"SBY001": rules.FlagNotMatched,
+	})
+	opts := &options{Settings: settings.Settings{
+		HistoryDB: path,
+		Regions:   map[string]string{"JKT*": "Jakarta", "BDG*": "Bandung"},
+	}}
+
+	tests := []struct {
+		name string
+		h    historyOptions
+		want []result.TrendPoint
+	}{
+		{"by region", historyOptions{by: byRegion, period: result.PerDay}, []result.TrendPoint{
+			{Period: "2025-02-20", Group: "", NotMatched: 1, Total: 1},
+			{Period: "2025-02-20", Group: "Bandung", Match: 1, Total: 1},
+			{Period: "2025-02-20", Group: "Jakarta", Match: 1, NotMatched: 1, Total: 2},
+		}},
+		{"parameter of a region", historyOptions{by: result.ByParameter, period: result.PerDay, region: "jakarta"}, []result.TrendPoint{
+			{Period: "2025-02-20", Group: "P1", Match: 1, NotMatched: 1, Total: 2},
+		}},
+		{"sites of a region", historyOptions{by: result.BySite, period: result.PerMonth, region: "Bandung"}, []result.TrendPoint{
+			{Period: "2025-02", Group: "BDG010", Match: 1, Total: 1},
+		}},
+		{"unknown region", historyOptions{by: byRegion, period: result.PerDay, region: "Surabaya"}, []result.TrendPoint{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.h.filter(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.h.trend(opts, filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trend = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	bad := historyOptions{by: result.BySite, period: result.PerDay, since: "20/02/2025"}
+	if _, err := bad.filter(opts); err == nil {
+		t.Error("filter accepted an invalid -since")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"parameterCheck/process"
+	"parameterCheck/registry"
+	"parameterCheck/rules"
+)
+
+// runLint validates the rule rows of the given config workbooks, or of every
+// workbook in the config directory, and returns the process exit code.
+func runLint(_ context.Context, opts *options, args []string) int {
+	workbooks := args
+	if len(workbooks) == 0 {
+		files, err := os.ReadDir(opts.ConfigDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read config directory: %v\n", err)
+			return 1
+		}
+		for _, file := range files {
+			if !file.IsDir() && strings.HasSuffix(strings.ToLower(file.Name()), ".xlsx") {
+				workbooks = append(workbooks, filepath.Join(opts.ConfigDir, file.Name()))
+			}
+		}
+	}
+
+	problems := 0
+	for _, path := range workbooks {
+		sheets, err := lintSheets(opts, path)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			problems++
+			continue
+		}
+		for _, sheet := range sheets {
+			rows, err := process.ReadConfigRows(path, sheet)
+			if err != nil {
+				fmt.Printf("%s: sheet %s: %v\n", path, sheet, err)
+				problems++
+				continue
+			}
+			for _, issue := range rules.Lint(rows) {
+				fmt.Printf("%s: %s\n", path, issue)
+				problems++
+			}
+		}
+	}
+
+	if problems > 0 {
+		fmt.Printf("%d problem(s) found in %d workbook(s)\n", problems, len(workbooks))
+		return 1
+	}
+	fmt.Printf("No problems found in %d workbook(s)\n", len(workbooks))
+	return 0
+}
+
+// lintSheets returns the sheets of the workbook at path that hold the rules
+// of a selected target. Workbooks no target registers are matched against
+// every target.
+func lintSheets(opts *options, path string) ([]string, error) {
+	sheets, err := process.SheetNames(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	registered := false
+	for _, t := range registry.Targets() {
+		if strings.Contains(name, t.Workbook) {
+			registered = true
+		}
+	}
+
+	var result []string
+	for _, sheet := range sheets {
+		for _, t := range registry.Targets() {
+			if registered && !strings.Contains(name, t.Workbook) {
+				continue
+			}
+			if t.MatchesSheet(sheet) && opts.selected(t.Vendor, t.Tech) {
+				result = append(result, sheet)
+				break
+			}
+		}
+	}
+	return result, nil
+}
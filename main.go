@@ -1,18 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/exec"
-	"parameterCheck/models"
+	"parameterCheck/dump"
+	"parameterCheck/manifest"
 	"parameterCheck/process"
+	"parameterCheck/registry"
+	"parameterCheck/result"
+	"parameterCheck/rules"
+	"parameterCheck/schedule"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/manifoldco/promptui"
 )
@@ -35,7 +41,18 @@ func installOLEDB() error {
 }
 
 func main() {
-	if !isOLEDBInstalled() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"interactive"}
+	}
+	os.Exit(run(args))
+}
+
+// ensureOLEDB installs the Access Database Engine when it is missing. Dumps
+// are read natively; the provider is only needed on Windows to write Access
+// result files.
+func ensureOLEDB(opts *options) {
+	if runtime.GOOS == "windows" && opts.OutputFormat == result.FormatAccess && !isOLEDBInstalled() {
 		fmt.Println("OLEDB is missing, installing now...")
 		if err := installOLEDB(); err != nil {
 			log.Fatal("Failed to install OLEDB driver:", err)
@@ -44,38 +61,20 @@ func main() {
 		fmt.Println("kukuhwikartomo.ext@huawei.com - 2025")
 		os.Exit(0)
 	}
-	start()
 }
 
-func start() {
+// start is the interactive mode: it asks whether to re-create the config
+// db from the workbooks and then checks every dump. It returns nil when
+// there is no workbook to check against.
+func start(ctx context.Context, opts *options) *runSummary {
 
-	files, err := os.ReadDir(models.ConfigDir)
+	workbooks, err := findWorkbooks(opts.ConfigDir)
 	if err != nil {
 		log.Fatalf("Failed to read config directory: %v", err)
 	}
 
-	var configHuawei, configNokia bool
-	var huaweiPath, nokiaPath string
-
-	for _, file := range files {
-		if !file.IsDir() {
-			name := strings.ToLower(file.Name())
-			fullPath := filepath.Join(models.ConfigDir, file.Name())
-			if strings.Contains(name, "huawei.xlsx") {
-				configHuawei = true
-				huaweiPath = fullPath
-			}
-			if strings.Contains(name, "nokia.xlsx") {
-				configNokia = true
-				nokiaPath = fullPath
-			}
-
-		}
-	}
-
-	if configHuawei || configNokia {
+	if len(workbooks) > 0 {
 
-		// if checkFileExists(filepath.Join(exeDir, "./dbconfig.db")) {
 		promptDbExists := promptui.Select{
 			Label: "This will re-create config db, continue?",
 			Items: []string{"Yes", "No"},
@@ -87,414 +86,403 @@ func start() {
 		}
 
 		if userSel == "Yes" {
-			_ = os.Remove("./dbconfig.db")
-
-			if configHuawei {
-				err = process.ImportExcelToSQLite(huaweiPath, "Huawei", "2G", "./dbconfig.db")
-				if err != nil {
-					log.Fatal("No '2G' sheet found in Huawei:", err)
-				}
-
-				err = process.ImportExcelToSQLite(huaweiPath, "Huawei", "4G", "./dbconfig.db")
-				if err != nil {
-					log.Fatal("No '4G' sheet found in Huawei:", err)
-				}
-
-			}
-
-			if configNokia {
-				err = process.ImportExcelToSQLite(nokiaPath, "Nokia", "2G", "./dbconfig.db")
-				if err != nil {
-					log.Fatal("No '2G' sheet found in Nokia:", err)
-				}
-
-				err = process.ImportExcelToSQLite(nokiaPath, "Nokia", "4G", "./dbconfig.db")
-				if err != nil {
-					log.Fatal("No '4G' sheet found in Nokia:", err)
-				}
-
+			_ = os.Remove(opts.ConfigDB)
+			if err := importConfig(opts, workbooks); err != nil {
+				log.Fatal(err)
 			}
-
-			process_dump(configHuawei, configNokia)
-			return
 		}
 
-		if userSel == "No" {
-			process_dump(configHuawei, configNokia)
-			return
-		}
-		// }
-
+		return process_dump(ctx, opts, workbooks)
 	}
-
+	return nil
 }
 
-func process_dump(configHuawei, configNokia bool) {
-	var queriesHw2g, queriesHw4g, queriesNok2g, queriesNok4g map[string]string
-
-	db, err := sql.Open("sqlite", "./dbconfig.db")
+// findWorkbooks returns the paths of the registered config workbooks found
+// in dir, keyed by workbook name.
+func findWorkbooks(dir string) (map[string]string, error) {
+	files, err := os.ReadDir(dir)
 	if err != nil {
-		log.Fatal(err)
-	}
-	defer db.Close()
-
-	log.Println("Preparing Config Query")
-
-	if configHuawei {
-		queriesHw2g, err = getConfigQueries(db, "SELECT * FROM Huawei_2G")
-		if err != nil {
-			log.Fatal(err)
-			queriesHw2g = nil
-		}
-		queriesHw4g, err = getConfigQueries(db, "SELECT * FROM Huawei_4G")
-		if err != nil {
-			log.Fatal(err)
-			queriesHw4g = nil
-		}
+		return nil, err
 	}
 
-	if configNokia {
-		queriesNok2g, err = getConfigQueries(db, "SELECT * FROM Nokia_2G")
-		if err != nil {
-			log.Fatal(err)
-			queriesNok2g = nil
-		}
-		queriesNok4g, err = getConfigQueries(db, "SELECT * FROM Nokia_4G")
-		if err != nil {
-			log.Fatal(err)
-			queriesNok4g = nil
+	workbooks := make(map[string]string)
+	for _, file := range files {
+		if !file.IsDir() {
+			name := strings.ToLower(file.Name())
+			for _, t := range registry.Targets() {
+				if strings.Contains(name, t.Workbook) {
+					workbooks[t.Workbook] = filepath.Join(dir, file.Name())
+				}
+			}
 		}
 	}
-
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		processVendorFiles(models.Huawei2gDumpDir, queriesHw2g, models.HuaweiVendorResult)
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		processVendorFiles(models.Huawei4gDumpDir, queriesHw4g, models.HuaweiVendorResult)
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		processVendorFiles(models.Nokia2gDumpDir, queriesNok2g, models.NokiaVendorResult)
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		processVendorFiles(models.Nokia4gDumpDir, queriesNok4g, models.NokiaVendorResult)
-	}()
-
-	wg.Wait()
-	log.Println("All vendor files processed.")
-	log.Println("kukuhwikartomo.ext@huawei.com - 2025")
+	return workbooks, nil
 }
 
-func generateQueries(rows *sql.Rows) (map[string]string, error) {
-
-	querySnippets := make(map[string][]string)
-
-	for rows.Next() {
-		var rec models.ConfigRecord
-
-		var nullProposed sql.NullString
-		if err := rows.Scan(&rec.TableName, &rec.ParamName, &rec.AttributeColumn, &rec.DataType, &rec.Operator, &nullProposed); err != nil {
-			return nil, fmt.Errorf("failed to scan config row: %w", err)
-		}
-		if nullProposed.Valid {
-			rec.ProposedValue = nullProposed.String
-		} else {
-			rec.ProposedValue = ""
-		}
-
-		attrs := strings.Split(rec.AttributeColumn, ";")
-		for i := range attrs {
-			attrs[i] = fmt.Sprintf("[%s]", strings.TrimSpace(attrs[i]))
+// importConfig imports every sheet of the workbooks that belongs to a
+// selected target into the config db.
+func importConfig(opts *options, workbooks map[string]string) error {
+	imported := make(map[string]bool)
+	for workbook, path := range workbooks {
+		sheets, err := process.SheetNames(path)
+		if err != nil {
+			return err
 		}
-		attrSelect := strings.Join(attrs, ", ")
-
-		currentExpr := fmt.Sprintf("IIF(%s IS NULL, '', CSTR(%s))", rec.ParamName, rec.ParamName)
-
-		// Build the ProposedValue expression based on the operator.
-		var proposedExpr string
-		switch strings.ToLower(rec.Operator) {
-		case "between":
-			parts := strings.Split(rec.ProposedValue, "to")
-			if len(parts) != 2 {
-				proposedExpr = currentExpr
-			} else {
-				lower := strings.TrimSpace(parts[0])
-				upper := strings.TrimSpace(parts[1])
-				proposedExpr = fmt.Sprintf("IIF(Val(CSTR(%s)) BETWEEN %s AND %s, CSTR(%s), \"%s\")", rec.ParamName, lower, upper, rec.ParamName, rec.ProposedValue)
+		for _, sheet := range sheets {
+			t, ok := sheetTarget(workbook, sheet)
+			if !ok {
+				log.Printf("Skipping sheet %s of %s: no technology registered for it", sheet, path)
+				continue
 			}
-		case "multi":
-			if rec.ProposedValue == "" {
-				proposedExpr = currentExpr
-			} else {
-				// Convert "10 & 20 & 40" to "10,20,40" for the INSTR check.
-				multiList := strings.ReplaceAll(rec.ProposedValue, " & ", ",")
-				proposedExpr = fmt.Sprintf("IIF(INSTR(\",\" & \"%s\" & \",\", \",\" & %s & \",\") > 0, \"%s\", %s)", multiList, currentExpr, rec.ProposedValue, currentExpr)
+			if !opts.selected(t.Vendor, t.Tech) {
+				continue
 			}
-		case "=":
-			if rec.ProposedValue == "" {
-				proposedExpr = currentExpr
-			} else {
-				// For "=" operator, output the constant proposed value.
-				proposedExpr = fmt.Sprintf("\"%s\"", rec.ProposedValue)
+			if err := process.ImportExcelToSQLite(path, t.Vendor, sheet, opts.ConfigDB); err != nil {
+				return fmt.Errorf("failed to import sheet '%s' of %s: %w", sheet, path, err)
 			}
-		default:
-			proposedExpr = currentExpr
-		}
-
-		var snippet string
-		if strings.ToLower(rec.Operator) == "multi" {
-
-			multiList := strings.ReplaceAll(rec.ProposedValue, " & ", ",")
-			snippet = fmt.Sprintf(`
-SELECT %s, "%s" AS Parameter, %s AS CurrentValue, "%s" AS ProposedValue,
-       IIF(INSTR("," & "%s" & ",", "," & %s & ",") > 0, "Match", "NotMatched") AS Flag
-FROM [%s]`,
-				attrSelect,
-				rec.ParamName,
-				currentExpr,
-				rec.ProposedValue,
-				multiList,
-				currentExpr,
-				rec.TableName,
-			)
-		} else {
-
-			snippet = fmt.Sprintf(`
-SELECT sub.*, IIF(sub.CurrentValue = sub.ProposedValue, "Match", "NotMatched") AS Flag
-FROM (
-    SELECT %s, "%s" AS Parameter, %s AS CurrentValue, %s AS ProposedValue
-    FROM [%s]
-) AS sub`,
-				attrSelect,
-				rec.ParamName,
-				currentExpr,
-				proposedExpr,
-				rec.TableName,
-			)
+			if !strings.EqualFold(sheet, t.Sheet) {
+				if err := process.RenameConfigTable(opts.ConfigDB, t.Vendor+"_"+sheet, t.ConfigTable()); err != nil {
+					return err
+				}
+			}
+			imported[strings.ToLower(t.ConfigTable())] = true
 		}
-
-		querySnippets[rec.TableName] = append(querySnippets[rec.TableName], snippet)
 	}
+	return dropStaleConfig(opts, imported)
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating config rows: %w", err)
+// dropStaleConfig drops the config tables of the selected targets that
+// the import did not write, so the rules of a sheet or workbook since
+// removed are not checked any more.
+func dropStaleConfig(opts *options, imported map[string]bool) error {
+	db, err := sql.Open("sqlite", opts.ConfigDB)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite DB: %w", err)
 	}
+	defer db.Close()
 
-	result := make(map[string]string)
-	for table, snippets := range querySnippets {
-		result[table] = strings.Join(snippets, " UNION ")
+	tables, err := process.ConfigTables(db)
+	if err != nil {
+		return err
 	}
-
-	return result, nil
+	for _, t := range registry.Targets() {
+		key := strings.ToLower(t.ConfigTable())
+		if !opts.selected(t.Vendor, t.Tech) || imported[key] || !tables[key] {
+			continue
+		}
+		if err := process.DropConfigTable(db, t.ConfigTable()); err != nil {
+			return err
+		}
+		log.Printf("Dropped config table %s: no %s sheet in the %s workbook", t.ConfigTable(), t.Tech, t.Vendor)
+	}
+	return nil
 }
 
-func getConfigQueries(db *sql.DB, query string) (map[string]string, error) {
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, err
+// sheetTarget returns the target whose rules are on a sheet of workbook.
+func sheetTarget(workbook, sheet string) (registry.Target, bool) {
+	for _, t := range registry.Targets() {
+		if t.Workbook == workbook && t.MatchesSheet(sheet) {
+			return t, true
+		}
 	}
-	defer rows.Close()
-
-	return generateQueries(rows)
+	return registry.Target{}, false
 }
 
-func processVendorFiles(folder string, queries map[string]string, outputFolder string) {
+// process_dump checks the dumps of every selected target against its rules
+// and returns the outcome of the run.
+func process_dump(ctx context.Context, opts *options, workbooks map[string]string) *runSummary {
+	summary := &runSummary{}
 
-	files, err := filepath.Glob(filepath.Join(folder, "*.mdb"))
+	db, err := sql.Open("sqlite", opts.ConfigDB)
 	if err != nil {
-		log.Fatalf("Error finding MDB files in %s: %v", folder, err)
+		log.Fatal(err)
 	}
-	filesAccdb, err := filepath.Glob(filepath.Join(folder, "*.accdb"))
+	defer db.Close()
+
+	log.Println("Loading Config Rules")
+
+	imported, err := process.ConfigTables(db)
 	if err != nil {
-		log.Fatalf("Error finding ACCDB files in %s: %v", folder, err)
-	}
-	files = append(files, filesAccdb...)
-	var wg sync.WaitGroup
-	for _, file := range files {
-		wg.Add(1)
-		go func(f string) {
-			defer wg.Done()
-			processSingleAccessFile(f, queries, outputFolder)
-		}(file)
+		log.Fatal(err)
 	}
-	wg.Wait()
-}
 
-func processSingleAccessFile(filePath string, queries map[string]string, outputFolder string) {
+	// Targets sharing an output directory share its writer and manifest.
+	// A manifest is only saved once the results it describes are.
+	writers := make(map[string]result.Results)
+	manifests := make(map[string]*manifest.Manifest)
+	defer func() {
+		for dir, w := range writers {
+			if err := w.Close(); err != nil {
+				summary.fail("Failed to close results in %s: %v", dir, err)
+				continue
+			}
+			if m := manifests[dir]; m != nil {
+				if err := m.Save(); err != nil {
+					summary.fail("Failed to save the manifest of %s: %v", dir, err)
+				}
+			}
+		}
+	}()
 
-	log.Printf("Processing file: %s", filePath)
-	sourceConnStr := "Provider=Microsoft.ACE.OLEDB.12.0;Data Source=" + filePath
-	sourceDB, err := sql.Open("adodb", sourceConnStr)
-	if err != nil {
-		log.Printf("Failed to open Access DB %s: %v", filePath, err)
-		return
+	var report result.Writer
+	if opts.ReportDir != "" {
+		path := filepath.Join(opts.ReportDir, "report_"+time.Now().Format("20060102_150405")+".xlsx")
+		report, err = result.OpenReport(path)
+		if err != nil {
+			log.Fatalf("Failed to start report %s: %v", path, err)
+		}
+		defer func() {
+			if err := report.Close(); err != nil {
+				summary.fail("Failed to write report %s: %v", path, err)
+				return
+			}
+			log.Printf("Report written to %s", path)
+		}()
 	}
-	defer sourceDB.Close()
 
-	resultData := make(map[string][]map[string]interface{})
-	for table, query := range queries {
-		rows, err := sourceDB.Query(query)
+	var history *result.History
+	if opts.HistoryDB != "" {
+		history, err = result.OpenHistory(opts.HistoryDB)
 		if err != nil {
-			log.Printf("Query failed on file %s, table %s: %v", filePath, table, err)
+			log.Fatalf("Failed to open history %s: %v", opts.HistoryDB, err)
+		}
+		defer func() {
+			if err := history.Close(); err != nil {
+				summary.fail("Failed to close history %s: %v", opts.HistoryDB, err)
+			}
+		}()
+	}
+
+	sched := schedule.New(opts.Workers, opts.DriverLimits)
+	for _, t := range registry.Targets() {
+		if _, ok := workbooks[t.Workbook]; !ok || !opts.selected(t.Vendor, t.Tech) {
 			continue
 		}
-		data, err := readRowsToMap(rows)
-		rows.Close()
+		// Workbooks need not have a sheet for every technology.
+		if !imported[strings.ToLower(t.ConfigTable())] {
+			continue
+		}
+		ruleSet, err := rules.Load(db, t.ConfigTable())
 		if err != nil {
-			log.Printf("Failed to read rows from file %s, table %s: %v", filePath, table, err)
+			summary.fail("No rules loaded for %s, skipping (run import-config?): %v", t.Name(), err)
 			continue
 		}
-		resultData[table] = data
-	}
 
-	newFile := filepath.Join(outputFolder, filepath.Base(filePath)+"_result.accdb")
-	// Add to actually create new access file with path
-
-	templateFile := "./EMPTY.accdb"
-	if err := copyFile(templateFile, newFile); err != nil {
-		log.Printf("Failed to copy template to new file %s: %v", newFile, err)
-		return
+		dumpDir := opts.DumpDir(t.Vendor, t.Tech)
+		resultDir := opts.OutputDir(t.Vendor, t.Tech)
+		out, ok := writers[resultDir]
+		if !ok {
+			out, err = result.Open(opts.OutputFormat, resultDir, opts.Template)
+			if err != nil {
+				summary.fail("Failed to open results in %s, skipping %s: %v", resultDir, t.Name(), err)
+				continue
+			}
+			writers[resultDir] = out
+			m, err := manifest.Load(resultDir)
+			if err != nil {
+				log.Printf("Ignoring the manifest of %s, checking all its dumps: %v", resultDir, err)
+				m = manifest.New(resultDir)
+			}
+			manifests[resultDir] = m
+		}
+		run := &targetRun{
+			ruleSet:   ruleSet,
+			rulesHash: rules.Hash(ruleSet),
+			out:       out,
+			manifest:  manifests[resultDir],
+		}
+		if report != nil {
+			run.extras = append(run.extras, report)
+		}
+		if history != nil {
+			run.extras = append(run.extras, history.Target(t.Vendor, t.Tech))
+		}
+		processVendorFiles(ctx, sched, opts, summary, t, dumpDir, run)
 	}
 
-	newAccessConnStr := "Provider=Microsoft.ACE.OLEDB.12.0;Data Source=" + newFile
-	newAccessDB, err := sql.Open("adodb", newAccessConnStr)
-	if err != nil {
-		log.Printf("Failed to open new Access DB %s: %v", newFile, err)
-		return
+	sched.Wait()
+	if ctx.Err() != nil {
+		log.Println("Run cancelled; the results of dumps checked so far are kept.")
+	} else {
+		log.Println("All vendor files processed.")
 	}
-	defer newAccessDB.Close()
+	log.Println("kukuhwikartomo.ext@huawei.com - 2025")
+	return summary
+}
 
-	if err := populateNewAccessFileFromData(newAccessDB, resultData); err != nil {
-		log.Printf("Failed to populate new Access DB %s: %v", newFile, err)
-	}
+// targetRun is what the checks of a target's dumps share: out stores the
+// results the manifest describes, extras are the report and history,
+// which take the findings of every dump of a run.
+type targetRun struct {
+	ruleSet   map[string][]rules.Rule
+	rulesHash string
+	out       result.Results
+	extras    []result.Writer
+	manifest  *manifest.Manifest
 }
 
-func readRowsToMap(rows *sql.Rows) ([]map[string]interface{}, error) {
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, err
-	}
-	var results []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// processVendorFiles queues a check of every dump of target in folder and
+// adds its outcome to summary.
+// Each check holds the driver of its dump reader, and adodb as well when
+// results are written to Access files.
+func processVendorFiles(ctx context.Context, sched *schedule.Scheduler, opts *options, summary *runSummary, target registry.Target, folder string, run *targetRun) {
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
+	var files []string
+	for _, pattern := range target.Patterns {
+		matches, err := filepath.Glob(filepath.Join(folder, pattern))
+		if err != nil {
+			log.Fatalf("Error finding %s files in %s: %v", pattern, folder, err)
 		}
-
-		rowMap := make(map[string]interface{})
-		for i, col := range columns {
-			if values[i] != nil {
-				rowMap[col] = fmt.Sprintf("%v", values[i])
-			} else {
-				rowMap[col] = ""
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		reader := target.Reader
+		var err error
+		if reader == "" {
+			reader, err = dump.ReaderFor(file)
+			if errors.Is(err, dump.ErrNotDump) {
+				log.Printf("Skipping %s: %v", file, err)
+				continue
 			}
 		}
-		results = append(results, rowMap)
+		stats := newDumpSummary(target.Name(), file)
+		summary.add(stats)
+		if err != nil {
+			log.Printf("Failed to open dump %s: %v", file, err)
+			stats.err = err
+			continue
+		}
+		drivers := []string{reader}
+		if opts.OutputFormat == result.FormatAccess && reader != dump.ReaderADODB {
+			drivers = append(drivers, dump.ReaderADODB)
+		}
+		sched.Submit(schedule.Job{
+			Group:   target.Name(),
+			Drivers: drivers,
+			Run: func() {
+				processSingleAccessFile(ctx, opts, run, reader, file, stats)
+			},
+		})
 	}
-	return results, rows.Err()
 }
 
-func copyFile(src, dst string) error {
-	sourceFileStat, err := os.Stat(src)
-	if err != nil {
-		return fmt.Errorf("failed to stat source file: %w", err)
+// dumpError logs the error that ended the work on a dump at step, telling
+// the file timeout and cancellation apart, and returns it for the summary.
+func dumpError(opts *options, step, filePath string, err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Printf("Check of %s timed out after %s, discarding its results", filePath, time.Duration(opts.FileTimeout))
+		return fmt.Errorf("timed out after %s", time.Duration(opts.FileTimeout))
+	case errors.Is(err, context.Canceled):
+		log.Printf("Check of %s cancelled, discarding its results", filePath)
+		return errors.New("cancelled")
 	}
+	log.Printf("Failed to %s %s: %v", step, filePath, err)
+	return err
+}
 
-	if !sourceFileStat.Mode().IsRegular() {
-		return fmt.Errorf("%s is not a regular file", src)
+// processSingleAccessFile checks one dump and streams its results to every
+// writer of run, within the file timeout of opts, recording the outcome in
+// stats. If any of them fails, or the run is cancelled, the dump's results
+// are discarded from all of them. A dump the manifest lists with the same
+// content and rules keeps its results, if they are still there, unless
+// opts.force is set: it is only checked again for the report and history,
+// or skipped without them.
+func processSingleAccessFile(ctx context.Context, opts *options, run *targetRun, reader, filePath string, stats *dumpSummary) {
+	if ctx.Err() != nil {
+		stats.skipped = true // cancelled while queued
+		return
 	}
-
-	source, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
+	if opts.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.FileTimeout))
+		defer cancel()
 	}
-	defer source.Close()
 
-	destination, err := os.Create(dst)
+	dumpHash, err := manifest.HashFile(ctx, filePath)
 	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer destination.Close()
-
-	if _, err := io.Copy(destination, source); err != nil {
-		return fmt.Errorf("failed to copy file: %w", err)
+		stats.err = dumpError(opts, "read dump", filePath, err)
+		return
 	}
-
-	return nil
-}
-
-func populateNewAccessFileFromData(newAccessDB *sql.DB, resultData map[string][]map[string]interface{}) error {
-	for table, rowsData := range resultData {
-		// Drop the table if it exists.
-		dropStmt := fmt.Sprintf("DROP TABLE [%s];", table)
-		_, _ = newAccessDB.Exec(dropStmt) // Ignore errors if table doesn't exist.
-
-		// If there is no data for this table, skip creation.
-		if len(rowsData) == 0 {
-			log.Printf("No data for table %s; skipping creation.", table)
-			continue
+	outs := append([]result.Writer{run.out}, run.extras...)
+	if e, ok := run.manifest.Lookup(filePath, dumpHash, run.rulesHash, opts.OutputFormat); ok && !opts.force {
+		has, err := run.out.Has(filePath)
+		switch {
+		case err != nil:
+			log.Printf("Checking unchanged dump %s again, its results could not be looked up: %v", filePath, err)
+		case !has:
+			log.Printf("Checking unchanged dump %s again, its results are missing", filePath)
+		case len(run.extras) == 0:
+			log.Printf("Skipping unchanged dump %s, checked %s", filePath, e.Checked.Format(time.DateTime))
+			stats.unchanged = true
+			stats.tables, stats.rules, stats.rows = e.Tables, e.Rules, e.Rows
+			for flag, n := range e.Flags {
+				stats.flags[flag] = n
+			}
+			return
+		default:
+			log.Printf("Keeping the results of unchanged dump %s, checked %s; checking it for the report and history", filePath, e.Checked.Format(time.DateTime))
+			stats.unchanged = true
+			outs = run.extras
 		}
-
-		// Use the first row to determine the column names.
-		var columns []string
-		for col := range rowsData[0] {
-			columns = append(columns, col)
+	}
+	defer func() {
+		if stats.unchanged {
+			return // its results and manifest entry stay as they are
 		}
-
-		// Build CREATE TABLE statement: All columns are defined as TEXT.
-		var colDefs []string
-		for _, col := range columns {
-			colDefs = append(colDefs, fmt.Sprintf("[%s] TEXT", col))
+		if stats.err != nil || len(stats.failures) > 0 {
+			run.manifest.Forget(filePath)
+			return
 		}
-		createStmt := fmt.Sprintf("CREATE TABLE [%s] (%s);", table, strings.Join(colDefs, ", "))
-		if _, err := newAccessDB.Exec(createStmt); err != nil {
-			fmt.Println(createStmt)
-			fmt.Println(err)
+		run.manifest.Record(filePath, manifest.Entry{
+			DumpHash:  dumpHash,
+			RulesHash: run.rulesHash,
+			Format:    opts.OutputFormat,
+			Checked:   time.Now(),
+			Tables:    stats.tables,
+			Rules:     stats.rules,
+			Rows:      stats.rows,
+			Flags:     stats.flags,
+		})
+	}()
 
-			return fmt.Errorf("failed to create table %s: %w", table, err)
-		}
+	log.Printf("Processing file: %s", filePath)
+	source, err := dump.OpenWith(ctx, reader, filePath)
+	if err != nil {
+		stats.err = dumpError(opts, "open dump", filePath, err)
+		return
+	}
 
-		// Build an INSERT statement based on the column order.
-		var colList []string
-		var placeholders []string
-		for _, col := range columns {
-			colList = append(colList, fmt.Sprintf("[%s]", col))
-			placeholders = append(placeholders, "?")
+	var files []result.FileWriter
+	abort := func() {
+		for _, f := range files {
+			if err := f.Abort(); err != nil {
+				log.Printf("Failed to discard results of %s: %v", filePath, err)
+			}
 		}
-		insertStmt := fmt.Sprintf("INSERT INTO [%s] (%s) VALUES (%s);", table, strings.Join(colList, ", "), strings.Join(placeholders, ", "))
-		stmt, err := newAccessDB.Prepare(insertStmt)
+	}
+	for _, out := range outs {
+		f, err := out.Open(filePath)
 		if err != nil {
-			return fmt.Errorf("failed to prepare insert statement for table %s: %w", table, err)
+			log.Printf("Failed to write results of %s: %v", filePath, err)
+			stats.err = err
+			source.Close()
+			abort()
+			return
 		}
-		defer stmt.Close()
+		files = append(files, f)
+	}
 
-		// Insert each row.
-		for _, row := range rowsData {
-			var values []interface{}
-			for _, col := range columns {
-				values = append(values, row[col])
-			}
-			if _, err := stmt.Exec(values...); err != nil {
-				log.Printf("failed to insert row into table %s: %v", table, err)
-			}
+	err = checkDump(ctx, filePath, source, run.ruleSet, files, time.Duration(opts.QueryTimeout), stats)
+	if err != nil {
+		stats.err = dumpError(opts, "check", filePath, err)
+		abort()
+		return
+	}
+	for _, f := range files {
+		if err := f.Close(); err != nil {
+			log.Printf("Failed to write results of %s: %v", filePath, err)
+			stats.err = err
 		}
-		log.Printf("Table [%s] created successfully with %d rows.", table, len(rowsData))
 	}
-	return nil
 }
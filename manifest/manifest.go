@@ -0,0 +1,156 @@
+// Package manifest records which dumps were checked against which rules,
+// so a run can skip the dumps whose content and rules have not changed
+// since their results were written.
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File is the name of the manifest kept in each output directory.
+const File = "manifest.json"
+
+// Entry describes the results written for a dump. The counts let a
+// skipped dump still take part in the summary of a run.
+type Entry struct {
+	DumpHash  string         `json:"dumpHash"`
+	RulesHash string         `json:"rulesHash"`
+	Format    string         `json:"format"`
+	Checked   time.Time      `json:"checked"`
+	Tables    int            `json:"tables"`
+	Rules     int            `json:"rules"`
+	Rows      int            `json:"rows"`
+	Flags     map[string]int `json:"flags,omitempty"`
+}
+
+// Manifest holds the entries of one output directory, keyed by absolute
+// dump path. It is safe for concurrent use.
+type Manifest struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	changed bool
+}
+
+// New returns an empty manifest for dir, replacing any saved one.
+func New(dir string) *Manifest {
+	return &Manifest{path: filepath.Join(dir, File), entries: make(map[string]Entry)}
+}
+
+// Load reads the manifest of dir. A missing manifest is empty.
+func Load(dir string) (*Manifest, error) {
+	m := New(dir)
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", m.path, err)
+	}
+	return m, nil
+}
+
+// Lookup returns the entry of a dump whose results were written from the
+// same content, rules and format, if there is one. The results themselves
+// may since have been removed; the caller checks they are still there.
+func (m *Manifest) Lookup(dumpPath, dumpHash, rulesHash, format string) (Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key(dumpPath)]
+	if !ok || e.DumpHash != dumpHash || e.RulesHash != rulesHash || e.Format != format {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Record sets the entry of a dump whose results were written.
+func (m *Manifest) Record(dumpPath string, e Entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key(dumpPath)] = e
+	m.changed = true
+}
+
+// Forget drops the entry of a dump whose results were discarded.
+func (m *Manifest) Forget(dumpPath string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[key(dumpPath)]; ok {
+		delete(m.entries, key(dumpPath))
+		m.changed = true
+	}
+}
+
+// Save writes the manifest if it changed, replacing the previous one only
+// once the new one is complete.
+func (m *Manifest) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.changed {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	m.changed = false
+	return nil
+}
+
+// HashFile returns the SHA-256 of the file at path. It stops with ctx's
+// error once ctx is done.
+func HashFile(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	buf := make([]byte, 1<<20)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := f.Read(buf)
+		h.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func key(dumpPath string) string {
+	if abs, err := filepath.Abs(dumpPath); err == nil {
+		return abs
+	}
+	return filepath.Clean(dumpPath)
+}
@@ -1,16 +1,5 @@
 package models
 
-const (
-	Huawei4gDumpDir    = "./dumpfiles/huawei/4g"
-	Huawei2gDumpDir    = "./dumpfiles/huawei/2g"
-	HuaweiVendorResult = "./output/huawei"
-
-	Nokia4gDumpDir    = "./dumpfiles/nokia/4g"
-	Nokia2gDumpDir    = "./dumpfiles/nokia/2g"
-	NokiaVendorResult = "./output/nokia"
-	ConfigDir         = "./config/"
-)
-
 type ConfigRecord struct {
 	TableName       string
 	ParamName       string
@@ -19,3 +8,10 @@ type ConfigRecord struct {
 	Operator        string
 	ProposedValue   string
 }
+
+// ConfigRow is a ConfigRecord together with where it was read from.
+type ConfigRow struct {
+	ConfigRecord
+	Sheet string
+	Row   int
+}
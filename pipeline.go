@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"parameterCheck/dump"
+	"parameterCheck/result"
+	"parameterCheck/rules"
+)
+
+// The evaluator hands records to the writers in batches of pipelineBatch,
+// with at most pipelineDepth batches in flight, so a dump's results never
+// sit in memory as a whole.
+const (
+	pipelineBatch = 1024
+	pipelineDepth = 8
+)
+
+// errStopped ends the evaluation of a dump whose writers failed.
+var errStopped = errors.New("pipeline stopped")
+
+// batch is a message from the evaluator to the writers: the start of a
+// table when columns is set, a table that could not be checked when failed
+// is set, the error ending the dump when err is set, records of the
+// current table otherwise.
+type batch struct {
+	table   string
+	columns []string
+	records []rules.Record
+	failed  error
+	err     error
+}
+
+// channelSink batches the records of rules.Evaluate onto a channel.
+type channelSink struct {
+	out     chan<- batch
+	stop    <-chan struct{}
+	records []rules.Record
+}
+
+func (s *channelSink) BeginTable(table string, columns []string) error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	return s.send(batch{table: table, columns: columns})
+}
+
+func (s *channelSink) Write(record rules.Record) error {
+	s.records = append(s.records, record)
+	if len(s.records) >= pipelineBatch {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *channelSink) flush() error {
+	if len(s.records) == 0 {
+		return nil
+	}
+	err := s.send(batch{records: s.records})
+	s.records = make([]rules.Record, 0, pipelineBatch)
+	return err
+}
+
+func (s *channelSink) send(b batch) error {
+	select {
+	case s.out <- b:
+		return nil
+	case <-s.stop:
+		return errStopped
+	}
+}
+
+// checkDump evaluates the rules against source in one goroutine while the
+// caller's goroutine passes the results on to files and counts them in
+// stats, and closes source once the evaluation ends. A failing table, or
+// one a writer rejects, is logged and recorded in stats.failures and the
+// next one evaluated; a table taking longer than queryTimeout (when not
+// zero) or a failing writer stops the dump. When ctx is done checkDump
+// returns at once with its error, leaving an evaluator stuck in a driver
+// call behind.
+func checkDump(ctx context.Context, filePath string, source dump.Source, ruleSet map[string][]rules.Rule, files []result.FileWriter, queryTimeout time.Duration, stats *dumpSummary) error {
+	tables := make([]string, 0, len(ruleSet))
+	for table := range ruleSet {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	batches := make(chan batch, pipelineDepth)
+	stop := make(chan struct{})
+	go func() {
+		defer source.Close()
+		defer close(batches)
+		sink := &channelSink{out: batches, stop: stop}
+		for _, table := range tables {
+			err := evaluateTable(ctx, source, table, ruleSet[table], sink, queryTimeout)
+			if errors.Is(err, errStopped) || ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				_ = sink.send(batch{err: fmt.Errorf("table %s timed out after %s", table, queryTimeout)})
+				return
+			}
+			if err := sink.flush(); err != nil {
+				return
+			}
+			if err != nil {
+				if err := sink.send(batch{table: table, failed: err}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Flag ends rules.ResultColumns, so it is the last column of a table.
+	flagCol := -1
+	var werr error
+	for {
+		select {
+		case <-ctx.Done():
+			if werr == nil {
+				close(stop)
+			}
+			return ctx.Err()
+		case b, ok := <-batches:
+			if !ok {
+				return werr
+			}
+			if werr != nil {
+				continue // drain until the evaluator sees stop
+			}
+			if b.err != nil {
+				return b.err
+			}
+			if b.failed != nil {
+				log.Printf("Check failed on file %s, table %s: %v", filePath, b.table, b.failed)
+				stats.failures = append(stats.failures, fmt.Sprintf("table %s: %v", b.table, b.failed))
+				continue
+			}
+			for _, f := range files {
+				werr = writeBatch(f, b)
+				if errors.Is(werr, result.ErrTableRejected) {
+					log.Printf("Check failed on file %s, table %s: %v", filePath, b.table, werr)
+					stats.failures = append(stats.failures, fmt.Sprintf("table %s: %v", b.table, werr))
+					werr = nil
+				}
+				if werr != nil {
+					close(stop)
+					break
+				}
+			}
+			if werr != nil {
+				continue
+			}
+			if b.columns != nil {
+				stats.tables++
+				stats.rules += len(ruleSet[b.table])
+				flagCol = len(b.columns) - 1
+			}
+			for _, record := range b.records {
+				stats.rows++
+				stats.flags[record[flagCol]]++
+			}
+		}
+	}
+}
+
+// evaluateTable runs rules.Evaluate on one table, within timeout when it
+// is not zero.
+func evaluateTable(ctx context.Context, source dump.Source, table string, tableRules []rules.Rule, sink rules.Sink, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return rules.Evaluate(ctx, source, table, tableRules, sink)
+}
+
+func writeBatch(f result.FileWriter, b batch) error {
+	if b.columns != nil {
+		return f.BeginTable(b.table, b.columns)
+	}
+	for _, record := range b.records {
+		if err := f.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
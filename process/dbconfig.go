@@ -2,59 +2,43 @@ package process
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 
-	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-adodb"
+	"github.com/xuri/excelize/v2"
 
 	// _ "github.com/mattn/go-sqlite3"
 	_ "modernc.org/sqlite"
+
+	"parameterCheck/models"
+	"parameterCheck/rules"
+	"parameterCheck/sqlquote"
 )
 
+// ImportExcelToSQLite copies a config sheet of an xlsx workbook into the
+// SQLite config database as table <tableName>_<sheetName>. The workbook is
+// read natively, so no OLEDB provider is needed. The first row holds the
+// column names; empty cells are stored as NULL. Sheets with an unknown
+// Operator or a malformed between range are rejected before anything is
+// written.
 func ImportExcelToSQLite(xlsxPath, tableName, sheetName, sqliteDBName string) error {
 
-	excelConnStr := fmt.Sprintf(`Provider=Microsoft.ACE.OLEDB.12.0;Data Source=%s;Extended Properties="Excel 12.0 Xml;HDR=YES;IMEX=1";`, xlsxPath)
-
-	// Open the Excel file via sqlx using the adodb driver.
-	excelDB, err := sqlx.Open("adodb", excelConnStr)
-	if err != nil {
-		return fmt.Errorf("failed to open Excel file: %w", err)
-	}
-	defer excelDB.Close()
-
-	// Build the query.
-	query := fmt.Sprintf("SELECT * FROM [%s$]", sheetName)
-
-	// Query the Excel file using Queryx to get column order.
-	rows, err := excelDB.Queryx(query)
+	columns, data, err := readExcelSheet(xlsxPath, sheetName)
 	if err != nil {
-		return fmt.Errorf("failed to query Excel sheet: %w", err)
-	}
-	defer rows.Close()
-
-	// Get the column names in the order returned by the query.
-	columns, err := rows.Columns()
-	if err != nil {
-		return fmt.Errorf("failed to get columns: %w", err)
+		return err
 	}
 	if len(columns) == 0 {
 		return fmt.Errorf("no columns found in Excel sheet")
 	}
-
-	// Read all rows into a slice of maps.
-	var data []map[string]interface{}
-	for rows.Next() {
-		m := make(map[string]interface{})
-		if err := rows.MapScan(m); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
-		}
-		data = append(data, m)
-	}
 	if len(data) == 0 {
 		return fmt.Errorf("no data found in Excel sheet")
 	}
+	if err := checkOperators(columns, data, sheetName); err != nil {
+		return err
+	}
 
 	// Open (or create) the SQLite database.
 	sqliteDB, err := sql.Open("sqlite", sqliteDBName)
@@ -64,18 +48,19 @@ func ImportExcelToSQLite(xlsxPath, tableName, sheetName, sqliteDBName string) er
 	defer sqliteDB.Close()
 
 	// Build a CREATE TABLE statement based on the Excel columns.
+	table := sqlquote.SQLite(tableName + "_" + sheetName)
 	var colDefs []string
 	for _, col := range columns {
 		// We'll use TEXT for all columns; adjust as needed.
-		colDefs = append(colDefs, fmt.Sprintf("`%s` TEXT", col))
+		colDefs = append(colDefs, sqlquote.SQLite(col)+" TEXT")
 	}
 
-	dropStmt := fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`;", tableName, sheetName)
+	dropStmt := fmt.Sprintf("DROP TABLE IF EXISTS %s;", table)
 	if _, err := sqliteDB.Exec(dropStmt); err != nil {
 		return fmt.Errorf("failed to drop table: %w", err)
 	}
 
-	createStmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s_%s` (%s);", tableName, sheetName, strings.Join(colDefs, ", "))
+	createStmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s);", table, strings.Join(colDefs, ", "))
 	if _, err := sqliteDB.Exec(createStmt); err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
@@ -84,10 +69,10 @@ func ImportExcelToSQLite(xlsxPath, tableName, sheetName, sqliteDBName string) er
 	var colList []string
 	var placeholders []string
 	for _, col := range columns {
-		colList = append(colList, fmt.Sprintf("`%s`", col))
+		colList = append(colList, sqlquote.SQLite(col))
 		placeholders = append(placeholders, "?")
 	}
-	insertStmt := fmt.Sprintf("INSERT INTO `%s_%s` (%s) VALUES (%s);", tableName, sheetName, strings.Join(colList, ", "), strings.Join(placeholders, ", "))
+	insertStmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", table, strings.Join(colList, ", "), strings.Join(placeholders, ", "))
 	stmt, err := sqliteDB.Prepare(insertStmt)
 	if err != nil {
 		return fmt.Errorf("failed to prepare insert statement: %w", err)
@@ -96,12 +81,7 @@ func ImportExcelToSQLite(xlsxPath, tableName, sheetName, sqliteDBName string) er
 
 	// Insert each row from Excel into SQLite.
 	for _, row := range data {
-		var values []interface{}
-		// Use the column order from the Excel query.
-		for _, col := range columns {
-			values = append(values, row[col])
-		}
-		if _, err := stmt.Exec(values...); err != nil {
+		if _, err := stmt.Exec(row.values...); err != nil {
 			log.Printf("failed to insert row: %v", err)
 		}
 	}
@@ -109,3 +89,194 @@ func ImportExcelToSQLite(xlsxPath, tableName, sheetName, sqliteDBName string) er
 	log.Printf("Data imported successfully into table %s_%s in %s", tableName, sheetName, sqliteDBName)
 	return nil
 }
+
+// SheetNames returns the names of the sheets of an xlsx workbook in order.
+func SheetNames(xlsxPath string) ([]string, error) {
+	f, err := excelize.OpenFile(xlsxPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer f.Close()
+	return f.GetSheetList(), nil
+}
+
+// checkOperators validates the Operator column of every row, and the range
+// of the between operators, so a typo is reported at import instead of
+// evaluating as a silent pass.
+func checkOperators(columns []string, data []sheetRow, sheetName string) error {
+	col, proposedCol := -1, -1
+	for i, name := range columns {
+		switch {
+		case strings.EqualFold(name, "Operator"):
+			col = i
+		case strings.EqualFold(name, "ProposedValue"):
+			proposedCol = i
+		}
+	}
+	if col < 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, row := range data {
+		op, _ := row.values[col].(string)
+		parsed, err := rules.ParseOperator(op)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sheet %s row %d: %w", sheetName, row.num, err))
+			continue
+		}
+		if proposedCol < 0 || (parsed != rules.OpBetween && parsed != rules.OpNotBetween) {
+			continue
+		}
+		// An empty range is the legacy "report the current value" rule.
+		proposed, _ := row.values[proposedCol].(string)
+		if strings.TrimSpace(proposed) == "" {
+			continue
+		}
+		if err := rules.ValidateRange(proposed); err != nil {
+			errs = append(errs, fmt.Errorf("sheet %s row %d: %w", sheetName, row.num, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sheetRow is a data row of a config sheet with its 1-based row number.
+type sheetRow struct {
+	num    int
+	values []interface{}
+}
+
+// readExcelSheet returns the header row of sheetName and the data rows below
+// it, one value per header column. Empty cells are nil and rows without any
+// value are skipped, the same way the ACE Excel driver returned them.
+func readExcelSheet(xlsxPath, sheetName string) ([]string, []sheetRow, error) {
+	f, err := excelize.OpenFile(xlsxPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(sheetName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query Excel sheet: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, nil
+	}
+
+	// Unnamed header cells get the F1, F2, ... names ACE used to give them.
+	columns := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fmt.Sprintf("F%d", i+1)
+		}
+		columns[i] = name
+	}
+
+	var data []sheetRow
+	for n, cells := range rows[1:] {
+		values := make([]interface{}, len(columns))
+		empty := true
+		for i := range columns {
+			if i < len(cells) && strings.TrimSpace(cells[i]) != "" {
+				values[i] = cells[i]
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		data = append(data, sheetRow{num: n + 2, values: values})
+	}
+	return columns, data, nil
+}
+
+// ReadConfigRows reads the rule rows of a config sheet, keeping their sheet
+// and row numbers for error reporting.
+func ReadConfigRows(xlsxPath, sheetName string) ([]models.ConfigRow, error) {
+	columns, data, err := readExcelSheet(xlsxPath, sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for i, name := range columns {
+		index[strings.ToLower(name)] = i
+	}
+	field := func(row sheetRow, name string) string {
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			return ""
+		}
+		s, _ := row.values[i].(string)
+		return s
+	}
+	for _, name := range []string{"TableName", "ParamName", "AttributeColumn", "DataType", "Operator", "ProposedValue"} {
+		if _, ok := index[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("sheet %s has no %s column", sheetName, name)
+		}
+	}
+
+	result := make([]models.ConfigRow, 0, len(data))
+	for _, row := range data {
+		result = append(result, models.ConfigRow{
+			ConfigRecord: models.ConfigRecord{
+				TableName:       strings.TrimSpace(field(row, "TableName")),
+				ParamName:       strings.TrimSpace(field(row, "ParamName")),
+				AttributeColumn: field(row, "AttributeColumn"),
+				DataType:        strings.TrimSpace(field(row, "DataType")),
+				Operator:        strings.TrimSpace(field(row, "Operator")),
+				ProposedValue:   field(row, "ProposedValue"),
+			},
+			Sheet: sheetName,
+			Row:   row.num,
+		})
+	}
+	return result, nil
+}
+
+// ConfigTables returns the tables of the config db, keyed in lower case.
+func ConfigTables(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables[strings.ToLower(name)] = true
+	}
+	return tables, rows.Err()
+}
+
+// DropConfigTable drops a table of the config db.
+func DropConfigTable(db *sql.DB, table string) error {
+	if _, err := db.Exec("DROP TABLE IF EXISTS " + sqlquote.SQLite(table)); err != nil {
+		return fmt.Errorf("failed to drop table %s: %w", table, err)
+	}
+	return nil
+}
+
+// RenameConfigTable renames an imported sheet table, replacing any table
+// already named to.
+func RenameConfigTable(sqliteDBName, from, to string) error {
+	sqliteDB, err := sql.Open("sqlite", sqliteDBName)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite DB: %w", err)
+	}
+	defer sqliteDB.Close()
+
+	if _, err := sqliteDB.Exec("DROP TABLE IF EXISTS " + sqlquote.SQLite(to)); err != nil {
+		return fmt.Errorf("failed to drop table: %w", err)
+	}
+	if _, err := sqliteDB.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", sqlquote.SQLite(from), sqlquote.SQLite(to))); err != nil {
+		return fmt.Errorf("failed to rename table %s: %w", from, err)
+	}
+	return nil
+}
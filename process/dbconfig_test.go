@@ -0,0 +1,137 @@
+package process
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+
+	"parameterCheck/models"
+	"parameterCheck/rules"
+)
+
+func TestCheckOperators(t *testing.T) {
+	columns := []string{"TableName", "ParamName", "Operator", "ProposedValue"}
+	row := func(num int, op, proposed interface{}) sheetRow {
+		return sheetRow{num: num, values: []interface{}{"T", "P", op, proposed}}
+	}
+
+	valid := []sheetRow{
+		row(2, "=", "5"),
+		row(3, "Between", "0 to 503"),
+		row(4, "not between", "(1 to 5]"),
+		row(5, "between", nil), // legacy: report the current value
+	}
+	if err := checkOperators(columns, valid, "4G"); err != nil {
+		t.Errorf("checkOperators(valid rows) = %v, want nil", err)
+	}
+
+	for _, r := range []sheetRow{
+		row(2, "~", "5"),
+		row(3, "between", "0 - 503"),
+		row(4, "not between", "9 to 1"),
+		row(5, "range", "low to high"),
+	} {
+		if err := checkOperators(columns, []sheetRow{r}, "4G"); err == nil {
+			t.Errorf("checkOperators(%v) = nil, want an error", r.values)
+		}
+	}
+}
+
+// FuzzImportConfig imports a config sheet of one fuzzed rule into SQLite
+// and loads it back with rules.Load, which must return the row as written.
+func FuzzImportConfig(f *testing.F) {
+	f.Add("Huawei", "GCELL", "CELLNAME", "NE;CELLID", "Integer", uint8(0), "5")
+	f.Add("ZTE", " EUtranCellFDD ", "pci", "", "Number", uint8(1), "0 to 503")
+	f.Add(`Ven"dor`, "Cell [1]", "a.b", "x\"; DROP TABLE t; --", "", uint8(2), "1 - 5")
+	f.Add("Ericsson", "T", " ", "NE", "Enum", uint8(3), "")
+	f.Add("v", "T", "P", "  NE ; CELL", " Bitfield ", uint8(4), " SW1-1&SW2-0 ")
+
+	operators := []string{"=", "between", "not between", "in", "regex", "is empty"}
+	f.Fuzz(func(t *testing.T, vendor, tableName, paramName, attrs, dataType string, op uint8, proposed string) {
+		fields := []string{vendor, tableName, paramName, attrs, dataType, proposed}
+		for _, s := range fields {
+			// Cells hold valid XML text of at most 32767 characters.
+			if !utf8.ValidString(s) || len(s) > 32767 || strings.ContainsFunc(s, func(r rune) bool {
+				return (r < 0x20 && r != '\t' && r != '\n') || r == 0xFFFE || r == 0xFFFF
+			}) || strings.Contains(s, "_x") {
+				t.Skip()
+			}
+		}
+		if vendor == "" || strings.HasPrefix(strings.ToLower(vendor), "sqlite_") {
+			t.Skip()
+		}
+		operator := operators[int(op)%len(operators)]
+
+		dir := t.TempDir()
+		xlsxPath := filepath.Join(dir, "config.xlsx")
+		wb := excelize.NewFile()
+		if err := wb.SetSheetName("Sheet1", "4G"); err != nil {
+			t.Fatal(err)
+		}
+		header := []interface{}{"TableName", "ParamName", "AttributeColumn", "DataType", "Operator", "ProposedValue"}
+		row := []interface{}{tableName, paramName, attrs, dataType, operator, proposed}
+		if err := wb.SetSheetRow("4G", "A1", &header); err != nil {
+			t.Fatal(err)
+		}
+		if err := wb.SetSheetRow("4G", "A2", &row); err != nil {
+			t.Fatal(err)
+		}
+		if err := wb.SaveAs(xlsxPath); err != nil {
+			t.Fatal(err)
+		}
+
+		dbPath := filepath.Join(dir, "config.db")
+		err := ImportExcelToSQLite(xlsxPath, vendor, "4G", dbPath)
+		badRange := (operator == "between" || operator == "not between") &&
+			strings.TrimSpace(proposed) != "" && rules.ValidateRange(proposed) != nil
+		if badRange {
+			if err == nil {
+				t.Fatalf("import accepted the range %q", proposed)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("import: %v", err)
+		}
+
+		db, err := sql.Open("sqlite", dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		ruleSet, err := rules.Load(db, vendor+"_4G")
+		if err != nil {
+			t.Fatalf("rules.Load: %v", err)
+		}
+
+		// Cells holding only blanks are stored as NULL.
+		cell := func(s string) string {
+			if strings.TrimSpace(s) == "" {
+				return ""
+			}
+			return s
+		}
+		want := models.ConfigRecord{
+			TableName:       strings.TrimSpace(tableName),
+			ParamName:       strings.TrimSpace(paramName),
+			AttributeColumn: cell(attrs),
+			DataType:        strings.TrimSpace(dataType),
+			Operator:        operator,
+			ProposedValue:   cell(proposed),
+		}
+		if want.TableName == "" || want.ParamName == "" {
+			if len(ruleSet) != 0 {
+				t.Fatalf("rules.Load returned %v for a row without a table or parameter", ruleSet)
+			}
+			return
+		}
+		got := ruleSet[want.TableName]
+		if len(ruleSet) != 1 || len(got) != 1 || got[0].ConfigRecord != want {
+			t.Fatalf("rules.Load = %+v, want one rule %+v", ruleSet, want)
+		}
+	})
+}
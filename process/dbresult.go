@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"strings"
+
+	"parameterCheck/sqlquote"
 )
 
 // ImportAccessQueryToSQLite imports the result of an Access query into SQLite.
@@ -55,7 +57,8 @@ func ImportAccessQueryToSQLite(rows *sql.Rows, tableName, sourceFile string, sql
 
 	// Open (or create) the SQLite table.
 	// Drop the table if it already exists.
-	dropStmt := fmt.Sprintf("DROP TABLE IF EXISTS `%s`;", tableName)
+	table := sqlquote.SQLite(tableName)
+	dropStmt := fmt.Sprintf("DROP TABLE IF EXISTS %s;", table)
 	if _, err := sqliteDB.Exec(dropStmt); err != nil {
 		return fmt.Errorf("failed to drop table: %w", err)
 	}
@@ -63,10 +66,10 @@ func ImportAccessQueryToSQLite(rows *sql.Rows, tableName, sourceFile string, sql
 	// Build a CREATE TABLE statement based on the Access columns.
 	var colDefs []string
 	for _, col := range columns {
-		// Use backticks to quote column names (handles spaces and special characters).
-		colDefs = append(colDefs, fmt.Sprintf("`%s` TEXT", col))
+		// Quote column names (handles spaces and special characters).
+		colDefs = append(colDefs, sqlquote.SQLite(col)+" TEXT")
 	}
-	createStmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` (%s);", tableName, strings.Join(colDefs, ", "))
+	createStmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s);", table, strings.Join(colDefs, ", "))
 	if _, err := sqliteDB.Exec(createStmt); err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
@@ -75,10 +78,10 @@ func ImportAccessQueryToSQLite(rows *sql.Rows, tableName, sourceFile string, sql
 	var colList []string
 	var placeholders []string
 	for _, col := range columns {
-		colList = append(colList, fmt.Sprintf("`%s`", col))
+		colList = append(colList, sqlquote.SQLite(col))
 		placeholders = append(placeholders, "?")
 	}
-	insertStmt := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s);", tableName, strings.Join(colList, ", "), strings.Join(placeholders, ", "))
+	insertStmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", table, strings.Join(colList, ", "), strings.Join(placeholders, ", "))
 
 	stmt, err := sqliteDB.Prepare(insertStmt)
 	if err != nil {
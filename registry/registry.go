@@ -0,0 +1,122 @@
+// Package registry declares the vendor/technology pairs the tool checks.
+// Each pair is registered once with its config sheet, dump files and dump
+// reader, and the import, check and report commands iterate over whatever
+// is registered.
+package registry
+
+import (
+	"strings"
+	"sync"
+)
+
+// Target is a vendor/technology pair.
+type Target struct {
+	Vendor string
+	Tech   string
+	// Workbook is the config workbook file name, matched without regard
+	// to case in the config directory.
+	Workbook string
+	// Sheet is the workbook sheet holding the rules of the pair.
+	Sheet string
+	// Patterns are the globs selecting dump files in the dump directory.
+	Patterns []string
+	// Reader is the dump reader used to open the dumps. When empty, each
+	// dump is opened with the reader for its file extension.
+	Reader string
+}
+
+// Name returns the pair as "<vendor> <tech>".
+func (t Target) Name() string {
+	return t.Vendor + " " + t.Tech
+}
+
+// ConfigTable returns the config db table the sheet is imported into.
+func (t Target) ConfigTable() string {
+	return t.Vendor + "_" + t.Sheet
+}
+
+// techAliases are the other names a technology's sheet may carry.
+var techAliases = map[string][]string{
+	"2G": {"GSM"},
+	"3G": {"UMTS", "WCDMA"},
+	"4G": {"LTE"},
+	"5G": {"NR", "5GNR", "5G NR"},
+}
+
+// MatchesSheet reports whether a workbook sheet holds the target's rules,
+// either by its Sheet name or by a common name of its technology.
+func (t Target) MatchesSheet(name string) bool {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, t.Sheet) {
+		return true
+	}
+	for _, alias := range techAliases[strings.ToUpper(t.Tech)] {
+		if strings.EqualFold(name, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	mu      sync.RWMutex
+	targets []Target
+)
+
+// Register adds t, replacing an earlier target of the same pair.
+func Register(t Target) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, existing := range targets {
+		if strings.EqualFold(existing.Vendor, t.Vendor) && strings.EqualFold(existing.Tech, t.Tech) {
+			targets[i] = t
+			return
+		}
+	}
+	targets = append(targets, t)
+}
+
+// Targets returns the registered targets in registration order.
+func Targets() []Target {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Target(nil), targets...)
+}
+
+// accessDumps are the patterns of Access database dumps.
+var accessDumps = []string{"*.mdb", "*.accdb"}
+
+// huaweiDumps are the Access dumps and raw CFGMML exports of Huawei.
+var huaweiDumps = append(append([]string(nil), accessDumps...), "*.txt", "*.mml")
+
+// nokiaDumps are the Access dumps and RAML exports of Nokia.
+var nokiaDumps = append(append([]string(nil), accessDumps...), "*.xml", "*.xml.gz")
+
+// ericssonDumps are the bulk CM exports of Ericsson.
+var ericssonDumps = []string{"*.xml", "*.xml.gz"}
+
+// zteDumps are the per-MO CSV (single or zipped) and workbook exports of ZTE.
+var zteDumps = []string{"*.csv", "*.zip", "*.xlsx"}
+
+func init() {
+	vendors := []struct {
+		name     string
+		patterns []string
+	}{
+		{"Huawei", huaweiDumps},
+		{"Nokia", nokiaDumps},
+		{"Ericsson", ericssonDumps},
+		{"ZTE", zteDumps},
+	}
+	for _, v := range vendors {
+		for _, tech := range []string{"2G", "3G", "4G", "5G"} {
+			Register(Target{
+				Vendor:   v.name,
+				Tech:     tech,
+				Workbook: strings.ToLower(v.name) + ".xlsx",
+				Sheet:    tech,
+				Patterns: v.patterns,
+			})
+		}
+	}
+}
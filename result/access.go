@@ -0,0 +1,226 @@
+package result
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/mattn/go-adodb"
+
+	"parameterCheck/rules"
+	"parameterCheck/sqlquote"
+)
+
+// accessWriter writes the results of each dump to <dump>_result.accdb,
+// a copy of the template filled through the ACE OLE DB provider.
+type accessWriter struct {
+	dir      string
+	template string
+}
+
+func (w *accessWriter) Open(dumpPath string) (FileWriter, error) {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory %s: %w", w.dir, err)
+	}
+	newFile := w.path(dumpPath)
+	if err := copyFile(w.template, newFile); err != nil {
+		return nil, fmt.Errorf("failed to copy template to new file %s: %w", newFile, err)
+	}
+
+	newAccessConnStr := "Provider=Microsoft.ACE.OLEDB.12.0;Data Source=" + newFile
+	newAccessDB, err := sql.Open("adodb", newAccessConnStr)
+	if err != nil {
+		os.Remove(newFile)
+		return nil, fmt.Errorf("failed to open new Access DB %s: %w", newFile, err)
+	}
+	return &accessFile{path: newFile, db: newAccessDB}, nil
+}
+
+// Has reports whether the result file of dumpPath exists.
+func (w *accessWriter) Has(dumpPath string) (bool, error) {
+	_, err := os.Stat(w.path(dumpPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// path returns the result file of dumpPath.
+func (w *accessWriter) path(dumpPath string) string {
+	return filepath.Join(w.dir, filepath.Base(dumpPath)+"_result.accdb")
+}
+
+func (w *accessWriter) Close() error {
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	sourceFileStat, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	if !sourceFileStat.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", src)
+	}
+
+	source, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer source.Close()
+
+	destination, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer destination.Close()
+
+	if _, err := io.Copy(destination, source); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return nil
+}
+
+// accessFile fills the result file of one dump. A table is created with
+// its first batch of records, so tables without results are left out.
+type accessFile struct {
+	path string
+	db   *sql.DB
+
+	table         string
+	quotedTable   string
+	quotedColumns []string
+	skip          bool
+	created       bool
+	rows          int
+	batch         []rules.Record
+}
+
+func (a *accessFile) BeginTable(table string, columns []string) error {
+	if err := a.endTable(); err != nil {
+		return err
+	}
+	a.table, a.created, a.rows, a.quotedColumns = table, false, 0, nil
+
+	a.skip = true
+	var err error
+	a.quotedTable, err = sqlquote.Access(table)
+	if err != nil {
+		return fmt.Errorf("%w to %s: %v", ErrTableRejected, filepath.Base(a.path), err)
+	}
+	for _, col := range columns {
+		quoted, err := sqlquote.Access(col)
+		if err != nil {
+			return fmt.Errorf("%w to %s: invalid column: %v", ErrTableRejected, filepath.Base(a.path), err)
+		}
+		a.quotedColumns = append(a.quotedColumns, quoted)
+	}
+	a.skip = false
+
+	// Drop the table if it exists.
+	dropStmt := fmt.Sprintf("DROP TABLE %s;", a.quotedTable)
+	_, _ = a.db.Exec(dropStmt) // Ignore errors if table doesn't exist.
+	return nil
+}
+
+func (a *accessFile) Write(record rules.Record) error {
+	if a.skip {
+		return nil
+	}
+	a.batch = append(a.batch, record)
+	if len(a.batch) >= BatchSize {
+		return a.flush()
+	}
+	return nil
+}
+
+// flush inserts the pending records in one transaction, creating the
+// table first if needed.
+func (a *accessFile) flush() error {
+	if len(a.batch) == 0 {
+		return nil
+	}
+	if !a.created {
+		// Build CREATE TABLE statement: All columns are defined as TEXT.
+		var colDefs []string
+		for _, col := range a.quotedColumns {
+			colDefs = append(colDefs, col+" TEXT")
+		}
+		createStmt := fmt.Sprintf("CREATE TABLE %s (%s);", a.quotedTable, strings.Join(colDefs, ", "))
+		if _, err := a.db.Exec(createStmt); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", a.table, err)
+		}
+		a.created = true
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Build an INSERT statement based on the column order.
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(a.quotedColumns)), ", ")
+	insertStmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);", a.quotedTable, strings.Join(a.quotedColumns, ", "), placeholders)
+	stmt, err := tx.Prepare(insertStmt)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement for table %s: %w", a.table, err)
+	}
+	defer stmt.Close()
+
+	for _, record := range a.batch {
+		values := make([]interface{}, len(record))
+		for i, v := range record {
+			values[i] = v
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return fmt.Errorf("failed to insert row into table %s: %w", a.table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to insert rows into table %s: %w", a.table, err)
+	}
+	a.rows += len(a.batch)
+	a.batch = a.batch[:0]
+	return nil
+}
+
+// endTable flushes the current table and logs its outcome.
+func (a *accessFile) endTable() error {
+	if a.table == "" || a.skip {
+		return nil
+	}
+	if err := a.flush(); err != nil {
+		return err
+	}
+	if a.rows == 0 {
+		log.Printf("No data for table %s; skipping creation.", a.table)
+	} else {
+		log.Printf("Table [%s] created successfully with %d rows.", a.table, a.rows)
+	}
+	return nil
+}
+
+func (a *accessFile) Close() error {
+	err := a.endTable()
+	if cerr := a.db.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to populate new Access DB %s: %w", a.path, err)
+	}
+	return nil
+}
+
+func (a *accessFile) Abort() error {
+	a.db.Close()
+	return os.Remove(a.path)
+}
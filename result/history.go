@@ -0,0 +1,387 @@
+package result
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"parameterCheck/rules"
+)
+
+// historySchema holds the findings of every run: a Runs row per run, a
+// Dumps row per dump checked, and a Findings row per result row. A
+// finding's Site is the value of its first key attribute; Attributes lists all of
+// them as name=value pairs. CheckedAt is set once all findings of a dump
+// are written.
+const historySchema = `
+CREATE TABLE IF NOT EXISTS Runs (
+	RunID      INTEGER PRIMARY KEY,
+	StartedAt  TEXT NOT NULL,
+	FinishedAt TEXT
+);
+CREATE TABLE IF NOT EXISTS Dumps (
+	DumpID    INTEGER PRIMARY KEY,
+	RunID     INTEGER NOT NULL REFERENCES Runs,
+	File      TEXT NOT NULL,
+	DumpDate  TEXT NOT NULL,
+	Vendor    TEXT NOT NULL,
+	Tech      TEXT NOT NULL,
+	CheckedAt TEXT
+);
+CREATE TABLE IF NOT EXISTS Findings (
+	DumpID        INTEGER NOT NULL REFERENCES Dumps,
+	TableName     TEXT NOT NULL,
+	Site          TEXT,
+	Attributes    TEXT,
+	Parameter     TEXT,
+	CurrentValue  TEXT,
+	ProposedValue TEXT,
+	Flag          TEXT
+);
+CREATE INDEX IF NOT EXISTS FindingsDump ON Findings (DumpID);
+CREATE INDEX IF NOT EXISTS FindingsParameter ON Findings (Parameter);
+CREATE INDEX IF NOT EXISTS FindingsSite ON Findings (Site);
+`
+
+// History appends the findings of a run to a SQLite database kept across
+// runs. Target returns the Writer for the dumps of one vendor/technology.
+// Writes of concurrent dumps are serialised, a transaction at a time.
+type History struct {
+	mu    sync.Mutex
+	db    *sql.DB
+	runID int64
+}
+
+// OpenHistory opens or creates the history database at path and starts a
+// run in it.
+func OpenHistory(path string) (*History, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history tables: %w", err)
+	}
+	res, err := db.Exec("INSERT INTO Runs (StartedAt) VALUES (?)", time.Now().Format(time.RFC3339))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to start run: %w", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &History{db: db, runID: runID}, nil
+}
+
+// Target returns the Writer recording the dumps of a vendor/technology.
+// Closing it leaves the History open.
+func (h *History) Target(vendor, tech string) Writer {
+	return &historyTarget{h: h, vendor: vendor, tech: tech}
+}
+
+// Close ends the run.
+func (h *History) Close() error {
+	_, err := h.db.Exec("UPDATE Runs SET FinishedAt = ? WHERE RunID = ?", time.Now().Format(time.RFC3339), h.runID)
+	if cerr := h.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// inTx runs fn in a transaction of its own.
+func (h *History) inTx(fn func(tx *sql.Tx) error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type historyTarget struct {
+	h      *History
+	vendor string
+	tech   string
+}
+
+// Open adds the dump to the run, dated as dumpDate says.
+func (t *historyTarget) Open(dumpPath string) (FileWriter, error) {
+	file, err := filepath.Abs(dumpPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	date := dumpDate(file, info.ModTime())
+	f := &historyFile{h: t.h}
+	err = t.h.inTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec("INSERT INTO Dumps (RunID, File, DumpDate, Vendor, Tech) VALUES (?, ?, ?, ?, ?)",
+			t.h.runID, file, date.Format(time.RFC3339), t.vendor, t.tech)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to the history: %w", file, err)
+		}
+		f.dumpID, err = res.LastInsertId()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (t *historyTarget) Close() error { return nil }
+
+// nameNumber matches the numbers of a file name, dashes included.
+var nameNumber = regexp.MustCompile(`[0-9]+(?:-[0-9]+)*`)
+
+// dumpDate returns the date of the export at path: the first number of its
+// file name that is a date from 2000 on, as YYYYMMDD or YYYY-MM-DD
+// ("HW_CFGMML_20250220.txt"), at midnight local time, or else modTime, as
+// a copied file may not keep when it was exported.
+func dumpDate(path string, modTime time.Time) time.Time {
+	for _, n := range nameNumber.FindAllString(filepath.Base(path), -1) {
+		for _, layout := range []string{"20060102", time.DateOnly} {
+			if d, err := time.ParseInLocation(layout, n, time.Local); err == nil && d.Year() >= 2000 {
+				return d
+			}
+		}
+	}
+	return modTime
+}
+
+// historyFile appends the findings of one dump, a transaction per batch.
+type historyFile struct {
+	h      *History
+	dumpID int64
+
+	table   string
+	columns []string
+	batch   []rules.Record
+}
+
+func (f *historyFile) BeginTable(table string, columns []string) error {
+	if err := f.flush(); err != nil {
+		return err
+	}
+	f.table, f.columns = table, columns
+	return nil
+}
+
+func (f *historyFile) Write(record rules.Record) error {
+	f.batch = append(f.batch, record)
+	if len(f.batch) >= BatchSize {
+		return f.flush()
+	}
+	return nil
+}
+
+func (f *historyFile) flush() error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	nAttrs := len(f.columns) - len(rules.ResultColumns)
+	err := f.h.inTx(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(`INSERT INTO Findings (DumpID, TableName, Site, Attributes, Parameter, CurrentValue, ProposedValue, Flag)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare insert statement for findings: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, record := range f.batch {
+			var attrs []string
+			for i, v := range record[:nAttrs] {
+				if v != "" {
+					attrs = append(attrs, f.columns[i]+"="+v)
+				}
+			}
+			site := ""
+			if nAttrs > 0 {
+				site = record[0]
+			}
+			r := record[nAttrs:]
+			if _, err := stmt.Exec(f.dumpID, f.table, site, strings.Join(attrs, ";"), r[0], r[1], r[2], r[3]); err != nil {
+				return fmt.Errorf("failed to insert finding of table %s: %w", f.table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	f.batch = f.batch[:0]
+	return nil
+}
+
+func (f *historyFile) Close() error {
+	if err := f.flush(); err != nil {
+		return err
+	}
+	return f.h.inTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE Dumps SET CheckedAt = ? WHERE DumpID = ?", time.Now().Format(time.RFC3339), f.dumpID)
+		return err
+	})
+}
+
+// Abort removes the dump and the findings written so far.
+func (f *historyFile) Abort() error {
+	return f.h.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM Findings WHERE DumpID = ?", f.dumpID); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM Dumps WHERE DumpID = ?", f.dumpID)
+		return err
+	})
+}
+
+// Trend groupings.
+const (
+	ByParameter = "parameter"
+	BySite      = "site"
+	ByTable     = "table"
+)
+
+// Trend periods.
+const (
+	PerDay   = "day"
+	PerMonth = "month"
+)
+
+// TrendFilter narrows a trend to some findings. Empty fields match all;
+// lists match any of their values and strings match without regard to
+// case. SiteFilter, when set, keeps the findings of the sites it accepts.
+type TrendFilter struct {
+	Vendors    []string
+	Techs      []string
+	Site       string
+	SiteFilter func(site string) bool
+	Table      string
+	Parameter  string
+	Since      time.Time
+}
+
+// TrendPoint counts the findings of one group in one period.
+type TrendPoint struct {
+	Period     string
+	Group      string
+	Match      int
+	NotMatched int
+	Total      int
+}
+
+// Trend returns the flag counts of the history at path per period of the
+// dump dates and per group, ordered by group and period. Only the latest
+// complete check of each dump counts, so dumps checked again, or skipped
+// as unchanged, are counted once.
+func Trend(path, by, period string, filter TrendFilter) ([]TrendPoint, error) {
+	groups := map[string]string{ByParameter: "f.Parameter", BySite: "f.Site", ByTable: "f.TableName"}
+	group, ok := groups[by]
+	if !ok {
+		return nil, fmt.Errorf("unknown trend grouping %q", by)
+	}
+	periods := map[string]string{PerDay: "substr(d.DumpDate, 1, 10)", PerMonth: "substr(d.DumpDate, 1, 7)"}
+	periodExpr, ok := periods[period]
+	if !ok {
+		return nil, fmt.Errorf("unknown trend period %q", period)
+	}
+	db, err := openHistoryQuery(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	where := []string{
+		"d.CheckedAt IS NOT NULL",
+		"d.DumpID IN (SELECT MAX(DumpID) FROM Dumps WHERE CheckedAt IS NOT NULL GROUP BY File, DumpDate)",
+	}
+	var args []interface{}
+	in := func(col string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		where = append(where, fmt.Sprintf("lower(%s) IN (?%s)", col, strings.Repeat(", ?", len(values)-1)))
+		for _, v := range values {
+			args = append(args, strings.ToLower(v))
+		}
+	}
+	eq := func(col, v string) {
+		if v != "" {
+			where = append(where, fmt.Sprintf("lower(%s) = ?", col))
+			args = append(args, strings.ToLower(v))
+		}
+	}
+	in("d.Vendor", filter.Vendors)
+	in("d.Tech", filter.Techs)
+	eq("f.Site", filter.Site)
+	eq("f.TableName", filter.Table)
+	eq("f.Parameter", filter.Parameter)
+	if !filter.Since.IsZero() {
+		where = append(where, "d.DumpDate >= ?")
+		args = append(args, filter.Since.Format(time.RFC3339))
+	}
+
+	// SiteFilter runs on the counts per site, which are then summed per
+	// group and period; the rows come ordered so those sums are adjacent.
+	site := "''"
+	if filter.SiteFilter != nil {
+		site = "COALESCE(f.Site, '')"
+	}
+	query := fmt.Sprintf(`SELECT %s, COALESCE(%s, ''), %s,
+			SUM(f.Flag = ?), SUM(f.Flag = ?), COUNT(*)
+		FROM Findings f JOIN Dumps d ON d.DumpID = f.DumpID
+		WHERE %s
+		GROUP BY 1, 2, 3 ORDER BY 2, 1`, periodExpr, group, site, strings.Join(where, " AND "))
+	args = append([]interface{}{rules.FlagMatch, rules.FlagNotMatched}, args...)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history %s: %w", path, err)
+	}
+	defer rows.Close()
+
+	var points []TrendPoint
+	for rows.Next() {
+		var p TrendPoint
+		var site string
+		if err := rows.Scan(&p.Period, &p.Group, &site, &p.Match, &p.NotMatched, &p.Total); err != nil {
+			return nil, err
+		}
+		if filter.SiteFilter != nil && !filter.SiteFilter(site) {
+			continue
+		}
+		if n := len(points); n > 0 && points[n-1].Period == p.Period && points[n-1].Group == p.Group {
+			points[n-1].Match += p.Match
+			points[n-1].NotMatched += p.NotMatched
+			points[n-1].Total += p.Total
+			continue
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// openHistoryQuery opens an existing history for reading.
+func openHistoryQuery(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return sql.Open("sqlite", path)
+}
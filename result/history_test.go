@@ -0,0 +1,147 @@
+package result
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"parameterCheck/rules"
+)
+
+func TestDumpDate(t *testing.T) {
+	modTime := time.Date(2025, 3, 4, 10, 30, 0, 0, time.Local)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.Local) }
+	tests := []struct {
+		path string
+		want time.Time
+	}{
+		{"/dumps/HW_MBTS_CFGMML_253_20250220_@1.accdb", day(2025, 2, 20)},
+		{"NSN_4G_Dump_20250303_JAVA_FL17.mdb", day(2025, 3, 3)},
+		{"20250105.xml", day(2025, 1, 5)},
+		{"bulk_2024-12-31.xml.gz", day(2024, 12, 31)},
+		{"cells_20251340_20250219.csv", day(2025, 2, 19)}, // the first is no date
+		{"/dumps/20250220/plan.xml", modTime},             // only the file name counts
+		{"BSC_123456789.txt", modTime},
+		{"plan.xml", modTime},
+	}
+	for _, tt := range tests {
+		if got := dumpDate(tt.path, modTime); !got.Equal(tt.want) {
+			t.Errorf("dumpDate(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// historyFinding is a result row of a dump written to the history.
+type historyFinding struct {
+	table, site, param, flag string
+}
+
+// writeHistory records a run of dumps, named after their dates, in a new
+// history and returns its path. A nil list of findings aborts the dump.
+func writeHistory(t *testing.T, vendor, tech string, dumps []string, findings [][]historyFinding) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.db")
+	h, err := OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := h.Target(vendor, tech)
+	for i, name := range dumps {
+		dump := filepath.Join(dir, name)
+		if err := os.WriteFile(dump, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := target.Open(dump)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns := append([]string{"NE Name"}, rules.ResultColumns...)
+		for _, fd := range findings[i] {
+			if err := f.BeginTable(fd.table, columns); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Write(rules.Record{fd.site, fd.param, "1", "1", fd.flag}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if findings[i] == nil {
+			err = f.Abort()
+		} else {
+			err = f.Close()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTrend(t *testing.T) {
+	const match, notMatched = rules.FlagMatch, rules.FlagNotMatched
+	path := writeHistory(t, "Huawei", "2G",
+		[]string{"BSC_20250220.txt", "BSC_20250221.txt", "BSC_20250220.txt", "BSC_20250301.txt"},
+		[][]historyFinding{
+			// Checked again below: only the later check counts.
+			{{"GCELL", "BSC1", "P1", notMatched}},
+			{
+				{"GCELL", "BSC1", "P1", match},
+				{"GCELL", "BSC2", "P1", notMatched},
+				{"GTRX", "BSC2", "P2", rules.FlagMissingParameter},
+			},
+			{
+				{"GCELL", "BSC1", "P1", match},
+				{"GCELL", "BSC2", "P2", match},
+			},
+			nil, // aborted
+		})
+
+	tests := []struct {
+		name   string
+		by     string
+		period string
+		filter TrendFilter
+		want   []TrendPoint
+	}{
+		{"by parameter", ByParameter, PerDay, TrendFilter{}, []TrendPoint{
+			{"2025-02-20", "P1", 1, 0, 1},
+			{"2025-02-21", "P1", 1, 1, 2},
+			{"2025-02-20", "P2", 1, 0, 1},
+			{"2025-02-21", "P2", 0, 0, 1},
+		}},
+		{"by site per month", BySite, PerMonth, TrendFilter{}, []TrendPoint{
+			{"2025-02", "BSC1", 2, 0, 2},
+			{"2025-02", "BSC2", 1, 1, 3},
+		}},
+		{"by table", ByTable, PerMonth, TrendFilter{Parameter: "p1"}, []TrendPoint{
+			{"2025-02", "GCELL", 2, 1, 3},
+		}},
+		// SiteFilter counts per site before summing per group.
+		{"by parameter of some sites", ByParameter, PerDay, TrendFilter{SiteFilter: func(site string) bool { return site == "BSC2" }}, []TrendPoint{
+			{"2025-02-21", "P1", 0, 1, 1},
+			{"2025-02-20", "P2", 1, 0, 1},
+			{"2025-02-21", "P2", 0, 0, 1},
+		}},
+		{"since", BySite, PerDay, TrendFilter{Since: time.Date(2025, 2, 21, 0, 0, 0, 0, time.Local)}, []TrendPoint{
+			{"2025-02-21", "BSC1", 1, 0, 1},
+			{"2025-02-21", "BSC2", 0, 1, 2},
+		}},
+		{"other vendor", BySite, PerDay, TrendFilter{Vendors: []string{"ZTE"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Trend(path, tt.by, tt.period, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Trend = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
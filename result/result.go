@@ -0,0 +1,60 @@
+// Package result writes the check results of dump files, either as one
+// Access file per dump copied from a template or into a SQLite database
+// per output directory, and optionally into an XLSX report per run and a
+// SQLite history kept across runs.
+package result
+
+import (
+	"errors"
+	"fmt"
+
+	"parameterCheck/rules"
+)
+
+// Output formats.
+const (
+	FormatAccess = "accdb"
+	FormatSQLite = "sqlite"
+)
+
+// BatchSize is the number of records a writer inserts per transaction.
+const BatchSize = 5000
+
+// ErrTableRejected is returned by BeginTable of a FileWriter that cannot
+// store a table, such as one whose name Access does not accept. The writer
+// ignores the records of that table and takes the next one.
+var ErrTableRejected = errors.New("table not written")
+
+// Writer stores the results of checked dumps. Open may be called
+// concurrently; each FileWriter is used by a single goroutine.
+type Writer interface {
+	Open(dumpPath string) (FileWriter, error)
+	Close() error
+}
+
+// Results is the Writer of an output directory's results, which can tell
+// whether the results of a dump are still there.
+type Results interface {
+	Writer
+	Has(dumpPath string) (bool, error)
+}
+
+// FileWriter receives the results of one dump as they are evaluated.
+// Close completes them; Abort discards what was written instead.
+type FileWriter interface {
+	rules.Sink
+	Close() error
+	Abort() error
+}
+
+// Open returns a writer of the given format storing results in dir.
+// template is the empty Access file Access results are copied from.
+func Open(format, dir, template string) (Results, error) {
+	switch format {
+	case FormatAccess:
+		return &accessWriter{dir: dir, template: template}, nil
+	case FormatSQLite:
+		return openSQLite(dir)
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
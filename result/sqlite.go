@@ -0,0 +1,329 @@
+package result
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"parameterCheck/rules"
+	"parameterCheck/sqlquote"
+)
+
+// SQLite result databases.
+const (
+	// SQLiteFile is the database written in each output directory.
+	SQLiteFile = "results.db"
+	// FilesTable indexes the dumps whose results the database holds.
+	FilesTable = "_files"
+	// ColFile names the dump a result row belongs to.
+	ColFile = "File"
+)
+
+// sqliteWriter writes the results of all dumps of an output directory to
+// one SQLite database: a table per dump table, whose rows carry the dump
+// they come from in the File column, and the FilesTable index. Results of
+// a dump checked again replace the earlier ones. Writes of concurrent
+// dumps are serialised, a transaction at a time.
+type sqliteWriter struct {
+	mu sync.Mutex
+	db *sql.DB
+	// columns caches the lower-cased columns of the result tables.
+	columns map[string]map[string]bool
+}
+
+func openSQLite(dir string) (*sqliteWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", filepath.Join(dir, SQLiteFile))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s TEXT PRIMARY KEY, CheckedAt TEXT, Tables INTEGER, Rows INTEGER)",
+		sqlquote.SQLite(FilesTable), sqlquote.SQLite(ColFile))
+	if _, err := db.Exec(create); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create %s: %w", FilesTable, err)
+	}
+	return &sqliteWriter{db: db, columns: make(map[string]map[string]bool)}, nil
+}
+
+// Open removes the earlier results of dumpPath.
+func (w *sqliteWriter) Open(dumpPath string) (FileWriter, error) {
+	file, err := filepath.Abs(dumpPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.inTx(func(tx *sql.Tx) error { return w.deleteFile(tx, file) }); err != nil {
+		return nil, err
+	}
+	return &sqliteFile{w: w, file: file}, nil
+}
+
+// Has reports whether FilesTable lists dumpPath, whose results are then
+// complete.
+func (w *sqliteWriter) Has(dumpPath string) (bool, error) {
+	file, err := filepath.Abs(dumpPath)
+	if err != nil {
+		return false, err
+	}
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE %s = ?", sqlquote.SQLite(FilesTable), sqlquote.SQLite(ColFile))
+	var one int
+	err = w.db.QueryRow(query, file).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// inTx runs fn in a transaction of its own.
+func (w *sqliteWriter) inTx(fn func(tx *sql.Tx) error) (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer func() {
+		// The rolled back transaction may have created tables or columns.
+		if err != nil {
+			clear(w.columns)
+		}
+	}()
+
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sqliteFile writes the results of one dump, a transaction per batch.
+// The dump is added to FilesTable once all its results are written.
+type sqliteFile struct {
+	w    *sqliteWriter
+	file string
+
+	table   string
+	columns []string
+	batch   []rules.Record
+	tables  int
+	rows    int
+}
+
+func (f *sqliteFile) BeginTable(table string, columns []string) error {
+	if err := f.flush(); err != nil {
+		return err
+	}
+	f.table, f.columns = table, renameReserved(columns)
+	f.tables++
+	return nil
+}
+
+func (f *sqliteFile) Write(record rules.Record) error {
+	f.batch = append(f.batch, record)
+	if len(f.batch) >= BatchSize {
+		return f.flush()
+	}
+	return nil
+}
+
+// flush inserts the pending records in a transaction of their own.
+func (f *sqliteFile) flush() error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	if err := f.w.inTx(f.insertBatch); err != nil {
+		return err
+	}
+	f.rows += len(f.batch)
+	f.batch = f.batch[:0]
+	return nil
+}
+
+// insertBatch inserts the pending records, creating the table or adding
+// the columns it lacks first.
+func (f *sqliteFile) insertBatch(tx *sql.Tx) error {
+	if len(f.batch) == 0 {
+		return nil
+	}
+	if err := f.w.ensureTable(tx, f.table, f.columns); err != nil {
+		return err
+	}
+
+	quoted := []string{sqlquote.SQLite(ColFile)}
+	for _, col := range f.columns {
+		quoted = append(quoted, sqlquote.SQLite(col))
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?%s)", sqlquote.SQLite(f.table),
+		strings.Join(quoted, ", "), strings.Repeat(", ?", len(f.columns)))
+	stmt, err := tx.Prepare(insert)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement for table %s: %w", f.table, err)
+	}
+	defer stmt.Close()
+
+	values := make([]interface{}, len(f.columns)+1)
+	values[0] = f.file
+	for _, record := range f.batch {
+		for i, v := range record {
+			values[i+1] = v
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return fmt.Errorf("failed to insert row into table %s: %w", f.table, err)
+		}
+	}
+	return nil
+}
+
+// Close inserts the last records and indexes the dump in one transaction,
+// so the dump is only listed in FilesTable with all its results.
+func (f *sqliteFile) Close() error {
+	return f.w.inTx(func(tx *sql.Tx) error {
+		if err := f.insertBatch(tx); err != nil {
+			return err
+		}
+		insert := fmt.Sprintf("INSERT INTO %s (%s, CheckedAt, Tables, Rows) VALUES (?, ?, ?, ?)",
+			sqlquote.SQLite(FilesTable), sqlquote.SQLite(ColFile))
+		rows := f.rows + len(f.batch)
+		if _, err := tx.Exec(insert, f.file, time.Now().Format(time.RFC3339), f.tables, rows); err != nil {
+			return fmt.Errorf("failed to index %s: %w", f.file, err)
+		}
+		return nil
+	})
+}
+
+// Abort removes the results written so far.
+func (f *sqliteFile) Abort() error {
+	return f.w.inTx(func(tx *sql.Tx) error { return f.w.deleteFile(tx, f.file) })
+}
+
+// deleteFile removes the earlier results of file.
+func (w *sqliteWriter) deleteFile(tx *sql.Tx, file string) error {
+	tables, err := w.tables(tx)
+	if err != nil {
+		return err
+	}
+	for _, table := range append(tables, FilesTable) {
+		del := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", sqlquote.SQLite(table), sqlquote.SQLite(ColFile))
+		if _, err := tx.Exec(del, file); err != nil {
+			return fmt.Errorf("failed to delete earlier results of %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// tables returns the result tables of the database.
+func (w *sqliteWriter) tables(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name <> ?", FilesTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// ensureTable creates table, or adds the columns it lacks, with an index
+// on ColFile so the results of a dump are found without a full scan.
+func (w *sqliteWriter) ensureTable(tx *sql.Tx, table string, columns []string) error {
+	key := strings.ToLower(table)
+	existing, ok := w.columns[key]
+	if !ok {
+		var err error
+		if existing, err = tableColumns(tx, table); err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			defs := []string{sqlquote.SQLite(ColFile) + " TEXT"}
+			for _, col := range columns {
+				defs = append(defs, sqlquote.SQLite(col)+" TEXT")
+			}
+			create := fmt.Sprintf("CREATE TABLE %s (%s)", sqlquote.SQLite(table), strings.Join(defs, ", "))
+			if _, err := tx.Exec(create); err != nil {
+				return fmt.Errorf("failed to create table %s: %w", table, err)
+			}
+			existing[strings.ToLower(ColFile)] = true
+			for _, col := range columns {
+				existing[strings.ToLower(col)] = true
+			}
+		}
+		// Tables of earlier versions were created without the index.
+		index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)",
+			sqlquote.SQLite("_"+table+"_"+ColFile), sqlquote.SQLite(table), sqlquote.SQLite(ColFile))
+		if _, err := tx.Exec(index); err != nil {
+			return fmt.Errorf("failed to index table %s: %w", table, err)
+		}
+		w.columns[key] = existing
+	}
+
+	for _, col := range columns {
+		if existing[strings.ToLower(col)] {
+			continue
+		}
+		alter := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT", sqlquote.SQLite(table), sqlquote.SQLite(col))
+		if _, err := tx.Exec(alter); err != nil {
+			return fmt.Errorf("failed to add column %s to table %s: %w", col, table, err)
+		}
+		existing[strings.ToLower(col)] = true
+	}
+	return nil
+}
+
+// renameReserved returns columns with those named as ColFile, or as an
+// earlier column, renamed to "<name>_2", "<name>_3" and so on, so a dump
+// attribute never takes the place of the column a writer adds.
+func renameReserved(columns []string) []string {
+	used := map[string]bool{strings.ToLower(ColFile): true}
+	renamed := make([]string, len(columns))
+	for i, col := range columns {
+		name := col
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s_%d", col, n)
+		}
+		used[strings.ToLower(name)] = true
+		renamed[i] = name
+	}
+	return renamed
+}
+
+// tableColumns returns the lower-cased columns of table, none if it does
+// not exist.
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, rows.Err()
+}
+
+func (w *sqliteWriter) Close() error {
+	return w.db.Close()
+}
@@ -0,0 +1,127 @@
+package result
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"parameterCheck/rules"
+)
+
+func TestRenameReserved(t *testing.T) {
+	tests := []struct {
+		columns []string
+		want    []string
+	}{
+		{[]string{"NE", "Parameter"}, []string{"NE", "Parameter"}},
+		{[]string{"File", "Parameter"}, []string{"File_2", "Parameter"}},
+		{[]string{"file", "File_2", "FILE"}, []string{"file_2", "File_2_2", "FILE_3"}},
+		{[]string{"NE", "ne", "Ne_2"}, []string{"NE", "ne_2", "Ne_2_2"}},
+		{nil, []string{}},
+	}
+	for _, tt := range tests {
+		if got := renameReserved(tt.columns); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("renameReserved(%q) = %q, want %q", tt.columns, got, tt.want)
+		}
+	}
+}
+
+// writeDump writes records to table as the results of dump.
+func writeDump(t *testing.T, w *sqliteWriter, dump, table string, columns []string, records ...rules.Record) {
+	t.Helper()
+	f, err := w.Open(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.BeginTable(table, columns); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range records {
+		if err := f.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func queryStrings(t *testing.T, db *sql.DB, query string, args ...interface{}) []string {
+	t.Helper()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestSQLiteRecheck(t *testing.T) {
+	w, err := openSQLite(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	columns := []string{"File", "Parameter", "Flag"}
+	writeDump(t, w, "/dumps/a.xml", "GCELL", columns, rules.Record{"x", "P1", "Match"}, rules.Record{"x", "P2", "NotMatched"})
+	writeDump(t, w, "/dumps/b.xml", "GCELL", columns, rules.Record{"y", "P1", "Match"})
+	// Checking a.xml again replaces its rows and leaves b.xml's alone.
+	writeDump(t, w, "/dumps/a.xml", "GCELL", columns, rules.Record{"z", "P3", "Match"})
+
+	got := queryStrings(t, w.db, `SELECT "File" || '|' || "File_2" || '|' || "Parameter" FROM "GCELL" ORDER BY 1`)
+	want := []string{"/dumps/a.xml|z|P3", "/dumps/b.xml|y|P1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GCELL rows = %q, want %q", got, want)
+	}
+	got = queryStrings(t, w.db, `SELECT "File" || '|' || Rows FROM "_files" ORDER BY 1`)
+	want = []string{"/dumps/a.xml|1", "/dumps/b.xml|1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("_files rows = %q, want %q", got, want)
+	}
+
+	for dump, want := range map[string]bool{"/dumps/a.xml": true, "/dumps/c.xml": false} {
+		if has, err := w.Has(dump); err != nil || has != want {
+			t.Errorf("Has(%s) = %v, %v; want %v", dump, has, err, want)
+		}
+	}
+}
+
+func TestSQLiteAbort(t *testing.T) {
+	w, err := openSQLite(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	columns := []string{"Parameter", "Flag"}
+	writeDump(t, w, "/dumps/a.xml", "GCELL", columns, rules.Record{"P1", "Match"})
+	f, err := w.Open("/dumps/a.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.BeginTable("GCELL", columns)
+	f.Write(rules.Record{"P2", "Match"})
+	f.BeginTable("GTRX", columns) // flushes GCELL
+	if err := f.Abort(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := queryStrings(t, w.db, `SELECT "Parameter" FROM "GCELL"`); len(got) != 0 {
+		t.Errorf("GCELL rows after Abort = %q, want none", got)
+	}
+	if has, err := w.Has("/dumps/a.xml"); err != nil || has {
+		t.Errorf("Has after Abort = %v, %v; want false", has, err)
+	}
+}
@@ -0,0 +1,508 @@
+package result
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xuri/excelize/v2"
+
+	"parameterCheck/rules"
+)
+
+// SummarySheet is the first sheet of an XLSX report.
+const SummarySheet = "Summary"
+
+// reportFlags are the flags counted on the summary sheet, in column order.
+var reportFlags = []string{
+	rules.FlagMatch,
+	rules.FlagNotMatched,
+	rules.FlagTypeMismatch,
+	rules.FlagInvalidRule,
+	rules.FlagMissingTable,
+	rules.FlagMissingParameter,
+	rules.FlagMissingAttribute,
+}
+
+// xlsxReport collects the results of a run and writes them to one
+// workbook on Close: a summary sheet counting the flags per table and per
+// parameter, then a sheet per dump table with the result rows of every
+// dump, the Flag cells coloured and the header row frozen. Result rows are
+// spilled to temporary files until then, so memory does not grow with the
+// size of the run.
+type xlsxReport struct {
+	path string
+	// dir holds the spilled rows.
+	dir string
+
+	mu     sync.Mutex
+	tables map[string]*reportTable
+}
+
+// reportTable gathers the results of a table across dumps.
+type reportTable struct {
+	name   string
+	pieces []reportPiece
+	// counts are the flag counts per parameter.
+	counts map[string]map[string]int
+	rows   int
+}
+
+// reportPiece holds the result rows of a table in one dump, spilled to a
+// CSV file with the dump's attribute columns.
+type reportPiece struct {
+	file       string
+	path       string
+	attributes []string
+	counts     map[string]map[string]int
+	rows       int
+}
+
+// OpenReport returns a writer collecting results into an XLSX report
+// written to path when it is closed.
+func OpenReport(path string) (Writer, error) {
+	dir, err := os.MkdirTemp("", "dumpchecker-report-")
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxReport{path: path, dir: dir, tables: make(map[string]*reportTable)}, nil
+}
+
+func (r *xlsxReport) Open(dumpPath string) (FileWriter, error) {
+	return &reportFile{r: r, file: filepath.Base(dumpPath)}, nil
+}
+
+// reportFile spills the results of one dump, a piece per table, and hands
+// the pieces to the report when closed.
+type reportFile struct {
+	r      *xlsxReport
+	file   string
+	tables []string
+	pieces []reportPiece
+
+	out *os.File
+	csv *csv.Writer
+}
+
+func (f *reportFile) BeginTable(table string, columns []string) error {
+	if err := f.endTable(); err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(f.r.dir, "*.csv")
+	if err != nil {
+		return err
+	}
+	f.out, f.csv = out, csv.NewWriter(out)
+	f.tables = append(f.tables, table)
+	f.pieces = append(f.pieces, reportPiece{
+		file:       f.file,
+		path:       out.Name(),
+		attributes: renameReserved(columns[:len(columns)-len(rules.ResultColumns)]),
+		counts:     make(map[string]map[string]int),
+	})
+	return nil
+}
+
+func (f *reportFile) Write(record rules.Record) error {
+	piece := &f.pieces[len(f.pieces)-1]
+	param, flag := record[len(record)-len(rules.ResultColumns)], record[len(record)-1]
+	if piece.counts[param] == nil {
+		piece.counts[param] = make(map[string]int)
+	}
+	piece.counts[param][flag]++
+	piece.rows++
+	return f.csv.Write(record)
+}
+
+// endTable completes the spill file of the current table.
+func (f *reportFile) endTable() error {
+	if f.out == nil {
+		return nil
+	}
+	f.csv.Flush()
+	err := f.csv.Error()
+	if cerr := f.out.Close(); err == nil {
+		err = cerr
+	}
+	f.out, f.csv = nil, nil
+	return err
+}
+
+func (f *reportFile) Close() error {
+	if err := f.endTable(); err != nil {
+		return err
+	}
+
+	f.r.mu.Lock()
+	defer f.r.mu.Unlock()
+	for i, piece := range f.pieces {
+		key := strings.ToLower(f.tables[i])
+		t, ok := f.r.tables[key]
+		if !ok {
+			t = &reportTable{name: f.tables[i], counts: make(map[string]map[string]int)}
+			f.r.tables[key] = t
+		}
+		t.pieces = append(t.pieces, piece)
+		t.rows += piece.rows
+		for param, counts := range piece.counts {
+			if t.counts[param] == nil {
+				t.counts[param] = make(map[string]int)
+			}
+			for flag, n := range counts {
+				t.counts[param][flag] += n
+			}
+		}
+	}
+	return nil
+}
+
+func (f *reportFile) Abort() error {
+	err := f.endTable()
+	for _, piece := range f.pieces {
+		os.Remove(piece.path)
+	}
+	return err
+}
+
+func (r *xlsxReport) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer os.RemoveAll(r.dir)
+
+	tables := make([]*reportTable, 0, len(r.tables))
+	for _, t := range r.tables {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].name < tables[j].name })
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", SummarySheet); err != nil {
+		return err
+	}
+	if err := writeSummary(f, tables); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	formats, err := flagFormats(f)
+	if err != nil {
+		return err
+	}
+	used := map[string]bool{strings.ToLower(SummarySheet): true}
+	for _, t := range tables {
+		if err := writeTableSheet(f, t, used, formats); err != nil {
+			return fmt.Errorf("failed to write sheet of table %s: %w", t.name, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return f.SaveAs(r.path)
+}
+
+// writeSummary writes the flag counts of every table, then of every
+// parameter of each table.
+func writeSummary(f *excelize.File, tables []*reportTable) error {
+	header := []interface{}{"Table", rules.ColParameter}
+	for _, flag := range reportFlags {
+		header = append(header, flag)
+	}
+	header = append(header, "Total", "Compliance")
+
+	var lines [][]interface{}
+	line := func(table, param string, counts map[string]int) []interface{} {
+		values := []interface{}{table, param}
+		total := 0
+		for _, flag := range reportFlags {
+			values = append(values, counts[flag])
+		}
+		for _, n := range counts {
+			total += n
+		}
+		values = append(values, total)
+		if checked := counts[rules.FlagMatch] + counts[rules.FlagNotMatched]; checked > 0 {
+			values = append(values, float64(counts[rules.FlagMatch])/float64(checked))
+		} else {
+			values = append(values, nil)
+		}
+		return values
+	}
+
+	for _, t := range tables {
+		tableCounts := make(map[string]int)
+		params := make([]string, 0, len(t.counts))
+		for param, counts := range t.counts {
+			params = append(params, param)
+			for flag, n := range counts {
+				tableCounts[flag] += n
+			}
+		}
+		lines = append(lines, line(t.name, "", tableCounts))
+		sort.Strings(params)
+		for _, param := range params {
+			lines = append(lines, line(t.name, param, t.counts[param]))
+		}
+	}
+
+	if err := f.SetSheetRow(SummarySheet, "A1", &header); err != nil {
+		return err
+	}
+	for i, values := range lines {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(SummarySheet, cell, &values); err != nil {
+			return err
+		}
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	percent, err := f.NewStyle(&excelize.Style{NumFmt: 10})
+	if err != nil {
+		return err
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	if err := f.SetCellStyle(SummarySheet, "A1", lastCol+"1", bold); err != nil {
+		return err
+	}
+	if len(lines) > 0 {
+		last := fmt.Sprintf("%s%d", lastCol, len(lines)+1)
+		if err := f.SetCellStyle(SummarySheet, lastCol+"2", last, percent); err != nil {
+			return err
+		}
+	}
+	// Table totals are the rows without a parameter.
+	for i, values := range lines {
+		if values[1] == "" {
+			row := i + 2
+			if err := f.SetCellStyle(SummarySheet, fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", lastCol, row), bold); err != nil {
+				return err
+			}
+			if err := f.SetCellStyle(SummarySheet, fmt.Sprintf("%s%d", lastCol, row), fmt.Sprintf("%s%d", lastCol, row), percent); err != nil {
+				return err
+			}
+		}
+	}
+	if err := f.SetColWidth(SummarySheet, "A", "B", 32); err != nil {
+		return err
+	}
+	return freezeHeader(f, SummarySheet)
+}
+
+// maxSheetRows is the number of rows of an Excel sheet, header included.
+var maxSheetRows = 1 << 20
+
+// writeTableSheet writes the result rows of t, led by the dump file, with
+// formats applied to the Flag column. The attribute columns are the union
+// of the dumps' ones. Rows beyond a sheet's limit continue on sheets named
+// "<table> (2)", "<table> (3)" and so on, each with the header row.
+func writeTableSheet(f *excelize.File, t *reportTable, used map[string]bool, formats []excelize.ConditionalFormatOptions) error {
+	sort.SliceStable(t.pieces, func(i, j int) bool { return t.pieces[i].file < t.pieces[j].file })
+	var attributes []string
+	index := make(map[string]int)
+	for _, piece := range t.pieces {
+		for _, attr := range piece.attributes {
+			if _, ok := index[strings.ToLower(attr)]; !ok {
+				index[strings.ToLower(attr)] = len(attributes)
+				attributes = append(attributes, attr)
+			}
+		}
+	}
+	columns := append([]string{ColFile}, attributes...)
+	columns = append(columns, rules.ResultColumns...)
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col
+	}
+
+	perSheet := maxSheetRows - 1
+	var sw *excelize.StreamWriter
+	sheets, row := 0, 0
+	newSheet := func() error {
+		if sw != nil {
+			if err := sw.Flush(); err != nil {
+				return err
+			}
+		}
+		sheets++
+		suffix := ""
+		if sheets > 1 {
+			suffix = fmt.Sprintf(" (%d)", sheets)
+		}
+		sheet := sheetName(t.name, suffix, used)
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+
+		// The stream writer keeps the sheet settings made before it is created.
+		rows := min(t.rows-(sheets-1)*perSheet, perSheet)
+		for i, col := range columns {
+			if strings.EqualFold(col, rules.ColFlag) {
+				name, _ := excelize.ColumnNumberToName(i + 1)
+				if err := f.SetConditionalFormat(sheet, fmt.Sprintf("%s2:%s%d", name, name, rows+1), formats); err != nil {
+					return err
+				}
+			}
+		}
+
+		var err error
+		if sw, err = f.NewStreamWriter(sheet); err != nil {
+			return err
+		}
+		if err := sw.SetPanes(frozenHeader()); err != nil {
+			return err
+		}
+		if err := sw.SetColWidth(1, len(columns), 18); err != nil {
+			return err
+		}
+		row = 2
+		return sw.SetRow("A1", header)
+	}
+	if err := newSheet(); err != nil {
+		return err
+	}
+
+	for _, piece := range t.pieces {
+		positions := make([]int, len(piece.attributes))
+		for i, attr := range piece.attributes {
+			positions[i] = 1 + index[strings.ToLower(attr)]
+		}
+		err := readPiece(piece, func(record []string) error {
+			if row > maxSheetRows {
+				if err := newSheet(); err != nil {
+					return err
+				}
+			}
+			values := make([]interface{}, len(columns))
+			values[0] = piece.file
+			for i := range values[1 : 1+len(attributes)] {
+				values[1+i] = ""
+			}
+			for i, pos := range positions {
+				values[pos] = record[i]
+			}
+			for i, v := range record[len(positions):] {
+				values[1+len(attributes)+i] = v
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, row)
+			row++
+			return sw.SetRow(cell, values)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+// readPiece passes the spilled records of piece to fn.
+func readPiece(piece reportPiece, fn func(record []string) error) error {
+	in, err := os.Open(piece.path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r := csv.NewReader(bufio.NewReader(in))
+	r.FieldsPerRecord = len(piece.attributes) + len(rules.ResultColumns)
+	r.ReuseRecord = true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// flagFormats returns the conditional formats colouring Flag cells: green
+// for Match, red for NotMatched and amber for the flags of rules that could
+// not be checked.
+func flagFormats(f *excelize.File) ([]excelize.ConditionalFormatOptions, error) {
+	fill := func(font, background string) (int, error) {
+		return f.NewConditionalStyle(&excelize.Style{
+			Font: &excelize.Font{Color: font},
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{background}},
+		})
+	}
+	green, err := fill("006100", "C6EFCE")
+	if err != nil {
+		return nil, err
+	}
+	red, err := fill("9C0006", "FFC7CE")
+	if err != nil {
+		return nil, err
+	}
+	amber, err := fill("9C5700", "FFEB9C")
+	if err != nil {
+		return nil, err
+	}
+
+	var formats []excelize.ConditionalFormatOptions
+	for _, flag := range reportFlags {
+		style := amber
+		switch flag {
+		case rules.FlagMatch:
+			style = green
+		case rules.FlagNotMatched:
+			style = red
+		}
+		formats = append(formats, excelize.ConditionalFormatOptions{
+			Type:     "cell",
+			Criteria: "==",
+			Format:   &style,
+			Value:    `"` + flag + `"`,
+		})
+	}
+	return formats, nil
+}
+
+func frozenHeader() *excelize.Panes {
+	return &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}
+}
+
+func freezeHeader(f *excelize.File, sheet string) error {
+	return f.SetPanes(sheet, frozenHeader())
+}
+
+// sheetName returns a unique sheet name for table, ending in suffix,
+// within Excel's limits.
+func sheetName(table, suffix string, used map[string]bool) string {
+	clean := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, table)
+	fit := func(suffix string) string {
+		runes := []rune(clean)
+		if n := 31 - len([]rune(suffix)); len(runes) > n {
+			runes = runes[:n]
+		}
+		return string(runes) + suffix
+	}
+	name := fit(suffix)
+	for n := 2; used[strings.ToLower(name)]; n++ {
+		name = fit(fmt.Sprintf("%s~%d", suffix, n))
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
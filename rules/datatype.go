@@ -0,0 +1,218 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DataType drives how a rule parses and compares parameter values.
+type DataType string
+
+const (
+	TypeString   DataType = "string"
+	TypeInteger  DataType = "integer"
+	TypeFloat    DataType = "float"
+	TypeEnum     DataType = "enum"
+	TypeBoolean  DataType = "boolean"
+	TypeBitfield DataType = "bitfield"
+)
+
+// dataTypeNames maps the DataType column spellings, including the legacy
+// "Number" and "Text" of the existing workbooks, to a DataType.
+var dataTypeNames = map[string]DataType{
+	"":         TypeString,
+	"string":   TypeString,
+	"text":     TypeString,
+	"integer":  TypeInteger,
+	"int":      TypeInteger,
+	"float":    TypeFloat,
+	"double":   TypeFloat,
+	"decimal":  TypeFloat,
+	"number":   TypeFloat,
+	"enum":     TypeEnum,
+	"boolean":  TypeBoolean,
+	"bool":     TypeBoolean,
+	"bitfield": TypeBitfield,
+	"bitmap":   TypeBitfield,
+}
+
+// ParseDataType resolves the DataType column of a config row.
+func ParseDataType(name string) (DataType, error) {
+	t, ok := dataTypeNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return TypeString, fmt.Errorf("unknown data type %q", name)
+	}
+	return t, nil
+}
+
+// value is a parameter value parsed according to a DataType.
+type value struct {
+	text    string
+	num     float64
+	numeric bool
+	bits    map[string]bool
+}
+
+// empty reports whether the value is missing.
+func (v value) empty() bool {
+	return v.text == ""
+}
+
+// parse parses s as a value of type t. Empty strings parse to the empty
+// value of every type.
+func (t DataType) parse(s string) (value, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return value{}, nil
+	}
+
+	switch t {
+	case TypeInteger, TypeFloat:
+		n, err := parseNumber(s)
+		if err != nil {
+			return value{}, fmt.Errorf("%q is not a number", s)
+		}
+		if t == TypeInteger && n != math.Trunc(n) {
+			return value{}, fmt.Errorf("%q is not an integer", s)
+		}
+		return value{text: strconv.FormatFloat(n, 'f', -1, 64), num: n, numeric: true}, nil
+	case TypeBoolean:
+		b, err := parseBool(s)
+		if err != nil {
+			return value{}, err
+		}
+		if b {
+			return value{text: "true", num: 1, numeric: true}, nil
+		}
+		return value{text: "false", numeric: true}, nil
+	case TypeBitfield:
+		bits, err := parseBitfield(s)
+		if err != nil {
+			return value{}, err
+		}
+		return value{text: formatBitfield(bits), bits: bits}, nil
+	case TypeEnum:
+		return value{text: strings.ToUpper(s)}, nil
+	default:
+		return value{text: s}, nil
+	}
+}
+
+// equal reports whether two parsed values are the same. Strings and enums
+// compare without regard to case, as Access did. A bitfield matches when
+// every switch named in want has the same state in got.
+func (t DataType) equal(got, want value) bool {
+	switch {
+	case got.numeric && want.numeric:
+		return floatEqual(got.num, want.num)
+	case t == TypeBitfield && got.bits != nil && want.bits != nil:
+		for name, on := range want.bits {
+			if state, ok := got.bits[name]; !ok || state != on {
+				return false
+			}
+		}
+		return true
+	default:
+		return strings.EqualFold(got.text, want.text)
+	}
+}
+
+// compare orders two parsed values: numerically when both are numbers,
+// otherwise as case-insensitive text.
+func (t DataType) compare(got, want value) int {
+	if got.numeric && want.numeric {
+		switch {
+		case floatEqual(got.num, want.num):
+			return 0
+		case got.num < want.num:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return strings.Compare(strings.ToLower(got.text), strings.ToLower(want.text))
+}
+
+func floatEqual(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// parseNumber parses integers and decimals written with either "." or ","
+// as the decimal separator and optional grouping, e.g. "1.0", "007",
+// "1,234.5", "1.234,5" or "1 234".
+func parseNumber(s string) (float64, error) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "_", "", "'", "").Replace(s)
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case dot >= 0 && comma >= 0:
+		// Whichever comes last is the decimal separator.
+		if comma > dot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case comma >= 0:
+		// A single comma not followed by exactly three digits is decimal.
+		if strings.Count(s, ",") == 1 && len(s)-comma-1 != 3 {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "-1", "true", "yes", "y", "on", "enable", "enabled":
+		return true, nil
+	case "0", "false", "no", "n", "off", "disable", "disabled":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not a boolean", s)
+}
+
+// parseBitfield parses switch lists such as "SW1-1&SW2-0" or
+// "SW1:ON,SW2:OFF" into switch states keyed by upper-case name.
+func parseBitfield(s string) (map[string]bool, error) {
+	bits := make(map[string]bool)
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return r == '&' || r == ',' || r == ';' }) {
+		item = strings.TrimSpace(item)
+		sep := strings.LastIndexAny(item, "-:=")
+		if sep <= 0 {
+			return nil, fmt.Errorf("%q is not a bitfield switch", item)
+		}
+		on, err := parseBool(strings.TrimSpace(item[sep+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("bitfield switch %q: %w", item, err)
+		}
+		bits[strings.ToUpper(strings.TrimSpace(item[:sep]))] = on
+	}
+	if len(bits) == 0 {
+		return nil, fmt.Errorf("%q is not a bitfield", s)
+	}
+	return bits, nil
+}
+
+func formatBitfield(bits map[string]bool) string {
+	names := make([]string, 0, len(bits))
+	for name := range bits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if bits[name] {
+			names[i] = name + "-1"
+		} else {
+			names[i] = name + "-0"
+		}
+	}
+	return strings.Join(names, "&")
+}
@@ -0,0 +1,200 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"parameterCheck/dump"
+)
+
+// Flags written to the result rows.
+const (
+	FlagMatch        = "Match"
+	FlagNotMatched   = "NotMatched"
+	FlagTypeMismatch = "TypeMismatch"
+	FlagInvalidRule  = "InvalidRule"
+
+	// A rule whose table or columns are missing from the dump is reported
+	// with one of these flags instead of being evaluated.
+	FlagMissingTable     = "MissingTable"
+	FlagMissingParameter = "MissingParameter"
+	FlagMissingAttribute = "MissingAttribute"
+)
+
+// Result columns following the attribute columns of each row.
+const (
+	ColParameter     = "Parameter"
+	ColCurrentValue  = "CurrentValue"
+	ColProposedValue = "ProposedValue"
+	ColFlag          = "Flag"
+)
+
+// ResultColumns are the columns following the attribute columns of every
+// result table.
+var ResultColumns = []string{ColParameter, ColCurrentValue, ColProposedValue, ColFlag}
+
+// Record is a result row, one value per column announced to its Sink.
+type Record []string
+
+// Sink receives the results of a table as they are evaluated: BeginTable
+// announces the columns, then every record is passed to Write. Records are
+// not reused, so a sink may keep them.
+type Sink interface {
+	BeginTable(table string, columns []string) error
+	Write(record Record) error
+}
+
+// Evaluate reads table from src once and evaluates every rule against each
+// of its rows, passing the results to sink without holding them. There is
+// one result row per dump row and rule, with the attributes the rule does
+// not name left empty. The columns are the attribute columns of the rules,
+// in AttributeColumn order and spelled as in the dump, followed by
+// ResultColumns. Identical result rows of consecutive dump rows with the
+// same attribute values are reported once, as the UNION of the generated
+// SQL used to; duplicates further apart are all reported. Each rule is
+// bound on its own: a rule naming a missing table or column yields a
+// single row flagged MissingTable, MissingParameter or MissingAttribute
+// while the other rules of the table are still evaluated. Evaluation stops
+// with ctx's error once ctx is done.
+func Evaluate(ctx context.Context, src dump.Source, table string, tableRules []Rule, sink Sink) error {
+	var columns []string
+	// attrIndex maps lower-cased attribute names to their result column.
+	attrIndex := make(map[string]int)
+	addAttribute := func(name string) int {
+		key := strings.ToLower(name)
+		i, ok := attrIndex[key]
+		if !ok {
+			i = len(columns)
+			attrIndex[key] = i
+			columns = append(columns, name)
+		}
+		return i
+	}
+	missingRecord := func(rule Rule, flag string) Record {
+		record := make(Record, len(columns))
+		copy(record[len(record)-len(ResultColumns):], []string{rule.ParamName, "", rule.ProposedValue, flag})
+		return record
+	}
+
+	rows, err := src.Rows(ctx, table)
+	if errors.Is(err, dump.ErrTableNotFound) {
+		for _, rule := range tableRules {
+			for _, attr := range rule.Attributes {
+				addAttribute(attr)
+			}
+		}
+		columns = append(columns, ResultColumns...)
+		if err := sink.BeginTable(table, columns); err != nil {
+			return err
+		}
+		for _, rule := range tableRules {
+			if err := sink.Write(missingRecord(rule, FlagMissingTable)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	dumpColumns := rows.Columns()
+	index := make(map[string]int, len(dumpColumns))
+	for i, col := range dumpColumns {
+		index[strings.ToLower(col)] = i
+	}
+
+	// Resolve every column a rule needs before reading any row.
+	type boundRule struct {
+		rule  Rule
+		param int
+		// attrs maps dump columns to result columns.
+		attrs [][2]int
+		// group holds the attribute values of the previous record and
+		// seen the records written since they last changed.
+		group string
+		seen  map[string]bool
+	}
+	type missingRule struct {
+		rule Rule
+		flag string
+	}
+	var missing []missingRule
+	bound := make([]boundRule, 0, len(tableRules))
+	for _, rule := range tableRules {
+		b := boundRule{rule: rule}
+		flag := ""
+		for _, attr := range rule.Attributes {
+			i, ok := index[strings.ToLower(attr)]
+			if !ok {
+				addAttribute(attr)
+				flag = FlagMissingAttribute
+				continue
+			}
+			b.attrs = append(b.attrs, [2]int{i, addAttribute(dumpColumns[i])})
+		}
+		param, ok := index[strings.ToLower(rule.ParamName)]
+		if !ok {
+			flag = FlagMissingParameter
+		}
+		if flag != "" {
+			missing = append(missing, missingRule{rule, flag})
+			continue
+		}
+		b.param = param
+		bound = append(bound, b)
+	}
+
+	nAttrs := len(columns)
+	columns = append(columns, ResultColumns...)
+	if err := sink.BeginTable(table, columns); err != nil {
+		return err
+	}
+	for _, m := range missing {
+		if err := sink.Write(missingRecord(m.rule, m.flag)); err != nil {
+			return err
+		}
+	}
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		values := rows.Values()
+		for i := range bound {
+			b := &bound[i]
+			current := values[b.param]
+			proposed, flag := check(b.rule, current)
+
+			record := make(Record, len(columns))
+			for _, a := range b.attrs {
+				record[a[1]] = values[a[0]]
+			}
+			copy(record[nAttrs:], []string{b.rule.ParamName, current, proposed, flag})
+
+			// Only the records of the current attribute values are kept, so
+			// memory is bounded by the longest run of rows sharing them.
+			if group := strings.Join(record[:nAttrs], "\x00"); group != b.group || b.seen == nil {
+				b.group, b.seen = group, make(map[string]bool)
+			}
+			k := strings.Join(record[nAttrs:], "\x00")
+			if b.seen[k] {
+				continue
+			}
+			b.seen[k] = true
+			if err := sink.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read table %s: %w", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"parameterCheck/dump"
+	"parameterCheck/models"
+)
+
+// memSource is a dump of a single table held in memory.
+type memSource struct {
+	columns []string
+	rows    [][]string
+}
+
+func (s *memSource) Tables() ([]string, error) { return []string{"T"}, nil }
+func (s *memSource) Close() error              { return nil }
+
+func (s *memSource) Rows(_ context.Context, table string) (dump.Rows, error) {
+	if table != "T" {
+		return nil, dump.ErrTableNotFound
+	}
+	return &memRows{src: s, i: -1}, nil
+}
+
+type memRows struct {
+	src *memSource
+	i   int
+}
+
+func (r *memRows) Columns() []string { return r.src.columns }
+func (r *memRows) Next() bool        { r.i++; return r.i < len(r.src.rows) }
+func (r *memRows) Values() []string  { return r.src.rows[r.i] }
+func (r *memRows) Err() error        { return nil }
+func (r *memRows) Close() error      { return nil }
+
+type recordSink struct {
+	records []string
+}
+
+func (s *recordSink) BeginTable(string, []string) error { return nil }
+func (s *recordSink) Write(record Record) error {
+	s.records = append(s.records, strings.Join(record, ","))
+	return nil
+}
+
+func TestEvaluateDuplicates(t *testing.T) {
+	src := &memSource{
+		columns: []string{"NE", "CELL", "P"},
+		rows: [][]string{
+			{"A", "1", "5"},
+			{"A", "2", "5"}, // same rule attributes and result as the row above
+			{"B", "1", "5"},
+			{"A", "1", "5"}, // a repeat further apart is reported again
+		},
+	}
+	rule := NewRule(models.ConfigRecord{TableName: "T", ParamName: "P", AttributeColumn: "NE", DataType: "Integer", Operator: "=", ProposedValue: "5"})
+	var sink recordSink
+	if err := Evaluate(context.Background(), src, "T", []Rule{rule}, &sink); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"A,P,5,5,Match", "B,P,5,5,Match", "A,P,5,5,Match"}
+	if !reflect.DeepEqual(sink.records, want) {
+		t.Errorf("Evaluate records = %q, want %q", sink.records, want)
+	}
+}
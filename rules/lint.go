@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"parameterCheck/models"
+)
+
+// Issue is a problem found in a config row.
+type Issue struct {
+	Sheet   string
+	Row     int
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("sheet %s row %d: %s", i.Sheet, i.Row, i.Message)
+}
+
+// Lint validates config rows: required fields, DataType and Operator
+// names, range and regex syntax, proposed values against the DataType, and
+// rules repeated for the same TableName/ParamName on a sheet.
+func Lint(rows []models.ConfigRow) []Issue {
+	var issues []Issue
+	first := make(map[string]int)
+
+	for _, row := range rows {
+		for _, err := range NewRule(row.ConfigRecord).Validate() {
+			issues = append(issues, Issue{Sheet: row.Sheet, Row: row.Row, Message: err.Error()})
+		}
+
+		key := strings.ToLower(row.Sheet + "\x00" + row.TableName + "\x00" + row.ParamName)
+		if prev, ok := first[key]; ok {
+			issues = append(issues, Issue{
+				Sheet:   row.Sheet,
+				Row:     row.Row,
+				Message: fmt.Sprintf("duplicate rule for %s/%s, first defined on row %d", row.TableName, row.ParamName, prev),
+			})
+			continue
+		}
+		first[key] = row.Row
+	}
+	return issues
+}
+
+// Validate returns every problem with the rule's definition.
+func (r Rule) Validate() []error {
+	var errs []error
+	if r.TableName == "" {
+		errs = append(errs, errors.New("TableName is empty"))
+	}
+	if r.ParamName == "" {
+		errs = append(errs, errors.New("ParamName is empty"))
+	}
+	if len(r.Attributes) == 0 {
+		errs = append(errs, errors.New("AttributeColumn is empty"))
+	}
+	if _, err := ParseDataType(r.DataType); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := ParseOperator(r.Operator); err != nil {
+		return append(errs, err)
+	}
+
+	proposed := strings.TrimSpace(r.ProposedValue)
+	numeric := r.Type == TypeInteger || r.Type == TypeFloat
+
+	switch r.Op {
+	case OpEmpty, OpNotEmpty:
+		if proposed != "" {
+			errs = append(errs, fmt.Errorf("operator %q takes no ProposedValue, got %q", r.Operator, proposed))
+		}
+		return errs
+	}
+	if proposed == "" {
+		if !r.Op.legacy() {
+			errs = append(errs, fmt.Errorf("operator %q needs a ProposedValue", r.Operator))
+		}
+		return errs
+	}
+
+	switch r.Op {
+	case OpBetween, OpNotBetween:
+		if _, err := parseRange(proposed); err != nil {
+			errs = append(errs, err)
+		}
+		if !numeric {
+			errs = append(errs, fmt.Errorf("operator %q needs a numeric DataType, got %q", r.Operator, r.DataType))
+		}
+	case OpIn, OpNotIn:
+		for _, option := range strings.Split(proposed, "&") {
+			if _, err := r.Type.parse(option); err != nil {
+				errs = append(errs, fmt.Errorf("value %v", err))
+			}
+		}
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+		if r.Type == TypeBoolean || r.Type == TypeBitfield {
+			errs = append(errs, fmt.Errorf("operator %q cannot order %s values", r.Operator, r.Type))
+		} else if _, err := r.Type.parse(proposed); err != nil {
+			errs = append(errs, fmt.Errorf("value %v", err))
+		}
+	case OpEqual, OpNotEqual:
+		if _, err := r.Type.parse(proposed); err != nil {
+			errs = append(errs, fmt.Errorf("value %v", err))
+		}
+	case OpRegex:
+		if r.pattern == nil {
+			errs = append(errs, fmt.Errorf("invalid regular expression %q", proposed))
+		}
+	}
+	return errs
+}
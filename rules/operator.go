@@ -0,0 +1,272 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operator is the comparison a rule applies between the current and the
+// proposed value.
+type Operator string
+
+const (
+	OpEqual        Operator = "="
+	OpNotEqual     Operator = "!="
+	OpLess         Operator = "<"
+	OpLessEqual    Operator = "<="
+	OpGreater      Operator = ">"
+	OpGreaterEqual Operator = ">="
+	OpBetween      Operator = "between"
+	OpNotBetween   Operator = "not between"
+	OpIn           Operator = "multi"
+	OpNotIn        Operator = "not in"
+	OpRegex        Operator = "regex"
+	OpPrefix       Operator = "prefix"
+	OpSuffix       Operator = "suffix"
+	OpContains     Operator = "contains"
+	OpNotContains  Operator = "not contains"
+	OpEmpty        Operator = "is empty"
+	OpNotEmpty     Operator = "is not empty"
+)
+
+// operatorNames maps the Operator column spellings to an Operator.
+var operatorNames = map[string]Operator{
+	"=":            OpEqual,
+	"==":           OpEqual,
+	"eq":           OpEqual,
+	"!=":           OpNotEqual,
+	"<>":           OpNotEqual,
+	"ne":           OpNotEqual,
+	"<":            OpLess,
+	"lt":           OpLess,
+	"<=":           OpLessEqual,
+	"le":           OpLessEqual,
+	">":            OpGreater,
+	"gt":           OpGreater,
+	">=":           OpGreaterEqual,
+	"ge":           OpGreaterEqual,
+	"between":      OpBetween,
+	"range":        OpBetween,
+	"not between":  OpNotBetween,
+	"outside":      OpNotBetween,
+	"multi":        OpIn,
+	"in":           OpIn,
+	"not in":       OpNotIn,
+	"not multi":    OpNotIn,
+	"regex":        OpRegex,
+	"matches":      OpRegex,
+	"prefix":       OpPrefix,
+	"starts with":  OpPrefix,
+	"suffix":       OpSuffix,
+	"ends with":    OpSuffix,
+	"contains":     OpContains,
+	"not contains": OpNotContains,
+	"is empty":     OpEmpty,
+	"empty":        OpEmpty,
+	"is not empty": OpNotEmpty,
+	"not empty":    OpNotEmpty,
+}
+
+// ParseOperator resolves the Operator column of a config row. Spelling is
+// matched without regard to case or repeated spaces.
+func ParseOperator(name string) (Operator, error) {
+	op, ok := operatorNames[strings.ToLower(strings.Join(strings.Fields(name), " "))]
+	if !ok {
+		return "", fmt.Errorf("unknown operator %q", name)
+	}
+	return op, nil
+}
+
+// legacy reports whether an empty proposed value means "report the current
+// value", as the original "=", "between" and "multi" rules did.
+func (o Operator) legacy() bool {
+	return o == OpEqual || o == OpBetween || o == OpIn
+}
+
+// check compares the current value of a parameter against the rule. It
+// returns the value to report as ProposedValue and the flag. A current
+// value that does not parse as the rule's DataType is flagged as a type
+// mismatch; rules with an unknown operator or a missing proposed value are
+// flagged as invalid rather than matched.
+func check(rule Rule, current string) (string, string) {
+	proposed := rule.ProposedValue
+
+	switch {
+	case rule.Op == "":
+		return proposed, FlagInvalidRule
+	case rule.Op == OpEmpty:
+		return proposed, flag(strings.TrimSpace(current) == "")
+	case rule.Op == OpNotEmpty:
+		return proposed, flag(strings.TrimSpace(current) != "")
+	case strings.TrimSpace(proposed) == "":
+		if rule.Op.legacy() {
+			return current, FlagMatch
+		}
+		return proposed, FlagInvalidRule
+	}
+
+	got, err := rule.Type.parse(current)
+	if err != nil {
+		return proposed, FlagTypeMismatch
+	}
+
+	switch rule.Op {
+	case OpEqual:
+		return proposed, flag(rule.Type.equal(got, rule.parseProposed(proposed)))
+	case OpNotEqual:
+		return proposed, flag(!rule.Type.equal(got, rule.parseProposed(proposed)))
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+		if got.empty() {
+			return proposed, FlagNotMatched
+		}
+		c := rule.Type.compare(got, rule.parseProposed(proposed))
+		switch rule.Op {
+		case OpLess:
+			return proposed, flag(c < 0)
+		case OpLessEqual:
+			return proposed, flag(c <= 0)
+		case OpGreater:
+			return proposed, flag(c > 0)
+		default:
+			return proposed, flag(c >= 0)
+		}
+	case OpBetween, OpNotBetween:
+		rng, err := parseRange(proposed)
+		if err != nil {
+			return proposed, FlagInvalidRule
+		}
+		n := got.num
+		if !got.numeric {
+			n = leadingNumber(current)
+		}
+		inside := !got.empty() && rng.contains(n)
+		if rule.Op == OpBetween && inside {
+			return current, FlagMatch
+		}
+		return proposed, flag(inside == (rule.Op == OpBetween))
+	case OpIn, OpNotIn:
+		// "10 & 20 & 40" lists the values.
+		found := false
+		for _, option := range strings.Split(proposed, "&") {
+			if rule.Type.equal(got, rule.parseProposed(option)) {
+				found = true
+				break
+			}
+		}
+		return proposed, flag(found == (rule.Op == OpIn))
+	case OpRegex:
+		if rule.pattern == nil {
+			return proposed, FlagInvalidRule
+		}
+		return proposed, flag(rule.pattern.MatchString(current))
+	case OpPrefix:
+		return proposed, flag(strings.HasPrefix(strings.ToLower(current), strings.ToLower(proposed)))
+	case OpSuffix:
+		return proposed, flag(strings.HasSuffix(strings.ToLower(current), strings.ToLower(proposed)))
+	case OpContains:
+		return proposed, flag(strings.Contains(strings.ToLower(current), strings.ToLower(proposed)))
+	case OpNotContains:
+		return proposed, flag(!strings.Contains(strings.ToLower(current), strings.ToLower(proposed)))
+	}
+	return proposed, FlagInvalidRule
+}
+
+// parseProposed parses a proposed value with the rule's DataType, falling
+// back to a plain string when it does not parse.
+func (r Rule) parseProposed(s string) value {
+	v, err := r.Type.parse(s)
+	if err != nil {
+		return value{text: strings.TrimSpace(s)}
+	}
+	return v
+}
+
+// valueRange is a numeric range written "1 to 5". Square or round brackets
+// around it make the bounds inclusive or exclusive, e.g. "(1 to 5]"; bare
+// ranges are inclusive.
+type valueRange struct {
+	lower, upper         float64
+	lowerOpen, upperOpen bool
+}
+
+func parseRange(s string) (valueRange, error) {
+	var r valueRange
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") || strings.HasPrefix(s, "[") {
+		r.lowerOpen = s[0] == '('
+		s = s[1:]
+	}
+	if strings.HasSuffix(s, ")") || strings.HasSuffix(s, "]") {
+		r.upperOpen = s[len(s)-1] == ')'
+		s = s[:len(s)-1]
+	}
+
+	parts := strings.Split(strings.ToLower(s), "to")
+	if len(parts) != 2 {
+		return r, fmt.Errorf("range %q is not written as \"lower to upper\"", s)
+	}
+	var err error
+	if r.lower, err = parseNumber(strings.TrimSpace(parts[0])); err != nil {
+		return r, fmt.Errorf("range lower bound %q is not a number", strings.TrimSpace(parts[0]))
+	}
+	if r.upper, err = parseNumber(strings.TrimSpace(parts[1])); err != nil {
+		return r, fmt.Errorf("range upper bound %q is not a number", strings.TrimSpace(parts[1]))
+	}
+	if r.lower > r.upper {
+		return r, fmt.Errorf("range lower bound %v is above upper bound %v", r.lower, r.upper)
+	}
+	return r, nil
+}
+
+// ValidateRange reports whether s is a range the between operators can
+// evaluate.
+func ValidateRange(s string) error {
+	_, err := parseRange(s)
+	return err
+}
+
+func (r valueRange) contains(n float64) bool {
+	if n < r.lower || (r.lowerOpen && n == r.lower) {
+		return false
+	}
+	if n > r.upper || (r.upperOpen && n == r.upper) {
+		return false
+	}
+	return true
+}
+
+func flag(match bool) string {
+	if match {
+		return FlagMatch
+	}
+	return FlagNotMatched
+}
+
+// leadingNumber parses the number at the start of s the way Access' Val()
+// does, returning 0 when there is none.
+func leadingNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	end, digits, dot := 0, false, false
+scan:
+	for i, c := range s {
+		switch {
+		case (c == '+' || c == '-') && i == 0:
+		case c >= '0' && c <= '9':
+			digits = true
+		case c == '.' && !dot:
+			dot = true
+		default:
+			break scan
+		}
+		end = i + 1
+	}
+	if !digits {
+		return 0
+	}
+	v, err := strconv.ParseFloat(strings.TrimRight(s[:end], "."), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
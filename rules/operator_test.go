@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"testing"
+
+	"parameterCheck/models"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		dataType  string
+		operator  string
+		proposed  string
+		current   string
+		wantFlag  string
+		wantValue string // reported ProposedValue, the proposed value when empty
+	}{
+		// Equality per data type.
+		{"string equal", "String", "=", "abc", "ABC", FlagMatch, ""},
+		{"string not equal", "String", "=", "abc", "abd", FlagNotMatched, ""},
+		{"integer equal", "Integer", "=", "7", "007", FlagMatch, ""},
+		{"integer type mismatch", "Integer", "=", "7", "7.5", FlagTypeMismatch, ""},
+		{"float equal", "Float", "=", "1.5", "1,5", FlagMatch, ""},
+		{"float grouping", "Number", "=", "1234.5", "1,234.5", FlagMatch, ""},
+		{"float type mismatch", "Float", "=", "1", "abc", FlagTypeMismatch, ""},
+		{"enum equal", "Enum", "=", "on", "ON", FlagMatch, ""},
+		{"enum not equal", "Enum", "=", "ON", "OFF", FlagNotMatched, ""},
+		{"boolean equal", "Boolean", "=", "true", "enabled", FlagMatch, ""},
+		{"boolean not equal", "Boolean", "=", "true", "0", FlagNotMatched, ""},
+		{"boolean type mismatch", "Boolean", "=", "true", "maybe", FlagTypeMismatch, ""},
+		{"bitfield subset", "Bitfield", "=", "SW1-1", "SW1-1&SW2-0", FlagMatch, ""},
+		{"bitfield differs", "Bitfield", "=", "SW1:ON", "SW1-0&SW2-1", FlagNotMatched, ""},
+		{"bitfield missing switch", "Bitfield", "=", "SW3-1", "SW1-1", FlagNotMatched, ""},
+		{"bitfield type mismatch", "Bitfield", "=", "SW1-1", "garbage", FlagTypeMismatch, ""},
+		{"unknown data type compares as string", "Colour", "=", "red", "RED", FlagMatch, ""},
+
+		{"not equal", "Integer", "!=", "5", "6", FlagMatch, ""},
+		{"not equal same", "Integer", "<>", "5", "5", FlagNotMatched, ""},
+
+		// Ordering.
+		{"less", "Integer", "<", "10", "9", FlagMatch, ""},
+		{"less equal bound", "Integer", "<", "10", "10", FlagNotMatched, ""},
+		{"less equal", "Float", "<=", "10", "10.0", FlagMatch, ""},
+		{"greater", "Integer", ">", "10", "11", FlagMatch, ""},
+		{"greater not", "Integer", "gt", "10", "2", FlagNotMatched, ""},
+		{"greater equal", "Integer", ">=", "10", "10", FlagMatch, ""},
+		{"ordering empty current", "Integer", ">=", "10", "", FlagNotMatched, ""},
+		{"string ordering", "String", "<", "b", "A", FlagMatch, ""},
+
+		// Ranges.
+		{"between inside reports current", "Integer", "Between", "0 to 503", "12", FlagMatch, "12"},
+		{"between outside", "Integer", "Between", "0 to 503", "900", FlagNotMatched, ""},
+		{"between open bound", "Integer", "between", "(0 to 10]", "0", FlagNotMatched, ""},
+		{"between closed bound", "Integer", "between", "(0 to 10]", "10", FlagMatch, "10"},
+		{"between leading number", "String", "range", "1 to 5", "3dB", FlagMatch, "3dB"},
+		{"between empty current", "Integer", "between", "1 to 5", "", FlagNotMatched, ""},
+		{"between malformed range", "Integer", "between", "1 - 5", "3", FlagInvalidRule, ""},
+		{"between reversed range", "Integer", "between", "5 to 1", "3", FlagInvalidRule, ""},
+		{"between legacy empty", "Integer", "between", "", "3", FlagMatch, "3"},
+		{"not between outside", "Integer", "not between", "1 to 5", "6", FlagMatch, ""},
+		{"not between inside", "Integer", "outside", "1 to 5", "3", FlagNotMatched, ""},
+		{"not between malformed range", "Integer", "not between", "x to 5", "3", FlagInvalidRule, ""},
+		{"not between empty", "Integer", "not between", "", "3", FlagInvalidRule, ""},
+
+		// Lists.
+		{"in", "Integer", "multi", "10 & 20 & 40", "20", FlagMatch, ""},
+		{"in missing", "Integer", "in", "10&20", "30", FlagNotMatched, ""},
+		{"in enum", "Enum", "in", "on&off", "OFF", FlagMatch, ""},
+		{"in legacy empty", "Integer", "multi", "", "30", FlagMatch, "30"},
+		{"not in", "Integer", "not in", "10&20", "30", FlagMatch, ""},
+		{"not in present", "Integer", "not multi", "10&20", "10", FlagNotMatched, ""},
+
+		// Text operators.
+		{"regex", "String", "regex", "^CELL[0-9]+$", "CELL12", FlagMatch, ""},
+		{"regex no match", "String", "matches", "^CELL[0-9]+$", "CELLX", FlagNotMatched, ""},
+		{"regex invalid", "String", "regex", "([", "x", FlagInvalidRule, ""},
+		{"prefix", "String", "prefix", "jkt", "JKT001", FlagMatch, ""},
+		{"prefix no match", "String", "starts with", "sby", "JKT001", FlagNotMatched, ""},
+		{"suffix", "String", "suffix", "01", "JKT001", FlagMatch, ""},
+		{"suffix no match", "String", "ends with", "02", "JKT001", FlagNotMatched, ""},
+		{"contains", "String", "contains", "kt0", "JKT001", FlagMatch, ""},
+		{"contains no match", "String", "contains", "xx", "JKT001", FlagNotMatched, ""},
+		{"not contains", "String", "not contains", "xx", "JKT001", FlagMatch, ""},
+		{"not contains present", "String", "not contains", "jkt", "JKT001", FlagNotMatched, ""},
+
+		// Presence.
+		{"is empty", "String", "is empty", "", " ", FlagMatch, ""},
+		{"is empty set", "String", "empty", "", "x", FlagNotMatched, ""},
+		{"is not empty", "Integer", "is not empty", "", "5", FlagMatch, ""},
+		{"is not empty missing", "Integer", "not empty", "", "", FlagNotMatched, ""},
+
+		// Invalid rules.
+		{"unknown operator", "Integer", "~", "5", "5", FlagInvalidRule, ""},
+		{"missing proposed", "Integer", "<", "", "5", FlagInvalidRule, ""},
+		{"equal legacy empty", "Integer", "=", "", "5", FlagMatch, "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewRule(models.ConfigRecord{
+				TableName:       "T",
+				ParamName:       "P",
+				AttributeColumn: "NE",
+				DataType:        tt.dataType,
+				Operator:        tt.operator,
+				ProposedValue:   tt.proposed,
+			})
+			gotValue, gotFlag := check(rule, tt.current)
+			wantValue := tt.wantValue
+			if wantValue == "" {
+				wantValue = tt.proposed
+			}
+			if gotFlag != tt.wantFlag || gotValue != wantValue {
+				t.Errorf("check(%s %s %q, %q) = %q, %q; want %q, %q",
+					tt.dataType, tt.operator, tt.proposed, tt.current, gotValue, gotFlag, wantValue, tt.wantFlag)
+			}
+		})
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	for _, s := range []string{"0 to 503", "(1 to 5]", "[-10 TO 10)", "1,5 to 2,5"} {
+		if err := ValidateRange(s); err != nil {
+			t.Errorf("ValidateRange(%q) = %v, want nil", s, err)
+		}
+	}
+	for _, s := range []string{"", "1 - 5", "a to b", "5 to 1", "1 to 2 to 3"} {
+		if err := ValidateRange(s); err == nil {
+			t.Errorf("ValidateRange(%q) = nil, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package rules evaluates the parameter rules of the config workbooks
+// against dump tables in Go, independent of the database driver.
+package rules
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"parameterCheck/models"
+	"parameterCheck/sqlquote"
+)
+
+// Rule is a config record prepared for evaluation.
+type Rule struct {
+	models.ConfigRecord
+
+	// Attributes are the AttributeColumn names identifying a row.
+	Attributes []string
+	// Type is the parsed DataType; unknown types compare as strings.
+	Type DataType
+	// Op is the parsed Operator; it is empty when the operator is unknown.
+	Op Operator
+
+	pattern *regexp.Regexp
+}
+
+// NewRule prepares rec for evaluation.
+func NewRule(rec models.ConfigRecord) Rule {
+	r := Rule{ConfigRecord: rec}
+	r.Type, _ = ParseDataType(rec.DataType)
+	r.Op, _ = ParseOperator(rec.Operator)
+	if r.Op == OpRegex {
+		r.pattern, _ = regexp.Compile(strings.TrimSpace(rec.ProposedValue))
+	}
+	for _, attr := range strings.Split(rec.AttributeColumn, ";") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			r.Attributes = append(r.Attributes, attr)
+		}
+	}
+	return r
+}
+
+// Load reads the rules of a config table (e.g. Huawei_2G) from the config
+// database, grouped by the dump table they apply to.
+func Load(db *sql.DB, configTable string) (map[string][]Rule, error) {
+	rows, err := db.Query("SELECT TableName, ParamName, AttributeColumn, DataType, Operator, ProposedValue FROM " + sqlquote.SQLite(configTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]Rule)
+	for rows.Next() {
+		var fields [6]sql.NullString
+		if err := rows.Scan(&fields[0], &fields[1], &fields[2], &fields[3], &fields[4], &fields[5]); err != nil {
+			return nil, fmt.Errorf("failed to scan config row: %w", err)
+		}
+		rec := models.ConfigRecord{
+			TableName:       strings.TrimSpace(fields[0].String),
+			ParamName:       strings.TrimSpace(fields[1].String),
+			AttributeColumn: fields[2].String,
+			DataType:        strings.TrimSpace(fields[3].String),
+			Operator:        strings.TrimSpace(fields[4].String),
+			ProposedValue:   fields[5].String,
+		}
+		if rec.TableName == "" || rec.ParamName == "" {
+			continue
+		}
+		result[rec.TableName] = append(result[rec.TableName], NewRule(rec))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating config rows: %w", err)
+	}
+	return result, nil
+}
+
+// Hash returns a digest of the config records of ruleSet, which changes
+// whenever a rule is added, removed or edited.
+func Hash(ruleSet map[string][]Rule) string {
+	tables := make([]string, 0, len(ruleSet))
+	for table := range ruleSet {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	h := sha256.New()
+	for _, table := range tables {
+		for _, r := range ruleSet[table] {
+			for _, field := range []string{r.TableName, r.ParamName, r.AttributeColumn, r.DataType, r.Operator, r.ProposedValue} {
+				h.Write([]byte(field))
+				h.Write([]byte{0})
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,134 @@
+// Package schedule runs dump checks on a fixed number of workers. Jobs are
+// taken round-robin from their groups, so one vendor or technology with
+// many dumps does not hold back the others, and each driver a job uses
+// can be capped below the worker count.
+package schedule
+
+import "sync"
+
+// Job is a unit of work.
+type Job struct {
+	// Group is the queue the job waits in, e.g. "Huawei/4G".
+	Group string
+	// Drivers name the resources the job holds while it runs, e.g. the
+	// reader of its dump.
+	Drivers []string
+	Run     func()
+}
+
+// Scheduler runs submitted jobs on a fixed number of workers, with at most
+// limits[d] jobs holding driver d at a time.
+type Scheduler struct {
+	limits map[string]int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	groups  []string
+	queues  map[string][]Job
+	next    int
+	pending int
+	running map[string]int
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// New starts a scheduler with the given number of workers, at least one.
+// Drivers missing from limits, or with a limit below one, are only bound by
+// the worker count.
+func New(workers int, limits map[string]int) *Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+	s := &Scheduler{
+		limits:  limits,
+		queues:  make(map[string][]Job),
+		running: make(map[string]int),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// Submit queues a job. It must not be called after Wait.
+func (s *Scheduler) Submit(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.queues[job.Group]; !ok {
+		s.groups = append(s.groups, job.Group)
+	}
+	s.queues[job.Group] = append(s.queues[job.Group], job)
+	s.pending++
+	s.cond.Broadcast()
+}
+
+// Wait runs the queued jobs to completion and stops the workers.
+func (s *Scheduler) Wait() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) work() {
+	defer s.wg.Done()
+	for {
+		s.mu.Lock()
+		job, ok := s.take()
+		for !ok {
+			if s.closed && s.pending == 0 {
+				s.mu.Unlock()
+				return
+			}
+			s.cond.Wait()
+			job, ok = s.take()
+		}
+		s.mu.Unlock()
+
+		job.Run()
+
+		s.mu.Lock()
+		for _, d := range job.Drivers {
+			s.running[d]--
+		}
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+// take removes the next job that can run, starting with the group after
+// the one served last, and acquires its drivers. Within a group, jobs run
+// in submission order unless a driver of the earlier ones is at its limit.
+// s.mu must be held.
+func (s *Scheduler) take() (Job, bool) {
+	for n := 0; n < len(s.groups); n++ {
+		g := (s.next + n) % len(s.groups)
+		queue := s.queues[s.groups[g]]
+		for i, job := range queue {
+			if !s.available(job.Drivers) {
+				continue
+			}
+			s.queues[s.groups[g]] = append(queue[:i:i], queue[i+1:]...)
+			s.pending--
+			s.next = g + 1
+			for _, d := range job.Drivers {
+				s.running[d]++
+			}
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// available reports whether every driver is below its limit.
+func (s *Scheduler) available(drivers []string) bool {
+	for _, d := range drivers {
+		if limit := s.limits[d]; limit > 0 && s.running[d] >= limit {
+			return false
+		}
+	}
+	return true
+}
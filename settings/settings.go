@@ -0,0 +1,302 @@
+// Package settings resolves the paths a run works with from defaults, an
+// optional JSON settings file and DUMPCHECKER_* environment variables, so
+// several teams can run the tool against different dumps on one host.
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvFile names the environment variable holding the settings file path.
+const EnvFile = "DUMPCHECKER_SETTINGS"
+
+// Settings are the input and output locations of a run and how many dumps
+// it checks at once.
+type Settings struct {
+	// ConfigDir holds the vendor config workbooks.
+	ConfigDir string `json:"configDir"`
+	// ConfigDB is the SQLite database the workbooks are imported into.
+	ConfigDB string `json:"configDb"`
+	// DumpRoot holds the dumps, laid out as <vendor>/<tech>.
+	DumpRoot string `json:"dumpRoot"`
+	// OutputRoot receives the result files, laid out as <vendor>.
+	OutputRoot string `json:"outputRoot"`
+	// Template is the empty Access file result files are copied from.
+	Template string `json:"template"`
+	// OutputFormat is the format results are written in: "accdb" for an
+	// Access file per dump, or "sqlite" for a database per output
+	// directory.
+	OutputFormat string `json:"outputFormat"`
+	// ReportDir, when set, receives an XLSX compliance report per run.
+	ReportDir string `json:"reportDir"`
+	// HistoryDB, when set, is the SQLite database every run appends its
+	// findings to.
+	HistoryDB string `json:"historyDb"`
+	// Regions maps site name patterns, as in path.Match, to the region
+	// the history groups those sites under, e.g. "JKT*": "Jakarta".
+	Regions map[string]string `json:"regions,omitempty"`
+	// Workers is the number of dumps checked at once.
+	Workers int `json:"workers"`
+	// DriverLimits caps the dumps checked at once per driver: a dump
+	// reader such as "jet" or "raml", or "adodb", which also covers the
+	// writing of Access result files.
+	DriverLimits map[string]int `json:"driverLimits,omitempty"`
+	// FileTimeout bounds the check of one dump, QueryTimeout the check of
+	// one of its tables. Zero means no limit.
+	FileTimeout  Duration `json:"fileTimeout"`
+	QueryTimeout Duration `json:"queryTimeout"`
+	// FailThreshold is the NotMatched share of the checked rows, in
+	// percent, above which a check exits with a compliance failure.
+	FailThreshold float64 `json:"failThreshold"`
+	// Targets overrides the directories of single vendor/technology
+	// pairs, keyed "<vendor>/<tech>", e.g. "Huawei/4G".
+	Targets map[string]TargetPaths `json:"targets,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "10m" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// TargetPaths are the directories of one vendor/technology pair. Empty
+// fields fall back to the layout under DumpRoot and OutputRoot.
+type TargetPaths struct {
+	DumpDir   string `json:"dumpDir,omitempty"`
+	OutputDir string `json:"outputDir,omitempty"`
+}
+
+// Default returns the settings of the original layout, relative to the
+// working directory.
+func Default() Settings {
+	return Settings{
+		ConfigDir:    "./config/",
+		ConfigDB:     "./dbconfig.db",
+		DumpRoot:     "./dumpfiles",
+		OutputRoot:   "./output",
+		Template:     "./EMPTY.accdb",
+		OutputFormat: "accdb",
+		Workers:      runtime.NumCPU(),
+		// The ACE provider does not cope with many connections at once.
+		DriverLimits: map[string]int{"adodb": 2},
+	}
+}
+
+// Load returns the default settings overlaid with the JSON file at path,
+// if any, and then with the environment. Relative paths in the file are
+// resolved against the file's directory.
+func Load(path string) (Settings, error) {
+	s := Default()
+	if path == "" {
+		path = os.Getenv(EnvFile)
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return s, fmt.Errorf("failed to read settings file: %w", err)
+		}
+		var file Settings
+		if err := json.Unmarshal(data, &file); err != nil {
+			return s, fmt.Errorf("failed to parse settings file %s: %w", path, err)
+		}
+		file.resolve(filepath.Dir(path))
+		s.Merge(file)
+	}
+
+	if err := s.mergeEnv(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// mergeEnv overlays s with the DUMPCHECKER_* variables that are set. The
+// numeric ones apply even when zero, so they can lift a limit of the
+// settings file.
+func (s *Settings) mergeEnv() error {
+	env := Settings{
+		ConfigDir:    os.Getenv("DUMPCHECKER_CONFIG_DIR"),
+		ConfigDB:     os.Getenv("DUMPCHECKER_CONFIG_DB"),
+		DumpRoot:     os.Getenv("DUMPCHECKER_DUMP_DIR"),
+		OutputRoot:   os.Getenv("DUMPCHECKER_OUTPUT_DIR"),
+		Template:     os.Getenv("DUMPCHECKER_TEMPLATE"),
+		OutputFormat: os.Getenv("DUMPCHECKER_OUTPUT_FORMAT"),
+		ReportDir:    os.Getenv("DUMPCHECKER_REPORT_DIR"),
+		HistoryDB:    os.Getenv("DUMPCHECKER_HISTORY_DB"),
+	}
+	limits, err := ParseLimits(os.Getenv("DUMPCHECKER_DRIVER_LIMITS"))
+	if err != nil {
+		return fmt.Errorf("invalid DUMPCHECKER_DRIVER_LIMITS: %w", err)
+	}
+	env.DriverLimits = limits
+	s.Merge(env)
+
+	if v := os.Getenv("DUMPCHECKER_WORKERS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid DUMPCHECKER_WORKERS %q: %w", v, err)
+		}
+		s.Workers = n
+	}
+	if v := os.Getenv("DUMPCHECKER_FAIL_THRESHOLD"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid DUMPCHECKER_FAIL_THRESHOLD %q: %w", v, err)
+		}
+		s.FailThreshold = f
+	}
+	for name, d := range map[string]*Duration{
+		"DUMPCHECKER_FILE_TIMEOUT":  &s.FileTimeout,
+		"DUMPCHECKER_QUERY_TIMEOUT": &s.QueryTimeout,
+	} {
+		if v := os.Getenv(name); v != "" {
+			t, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: %w", name, v, err)
+			}
+			*d = Duration(t)
+		}
+	}
+	return nil
+}
+
+// ParseLimits parses driver limits written as "adodb=1,raml=2".
+func ParseLimits(list string) (map[string]int, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	limits := make(map[string]int)
+	for _, item := range strings.Split(list, ",") {
+		driver, v, ok := strings.Cut(item, "=")
+		driver = strings.ToLower(strings.TrimSpace(driver))
+		if !ok || driver == "" {
+			return nil, fmt.Errorf("expected driver=limit, got %q", item)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit for %s: %w", driver, err)
+		}
+		limits[driver] = n
+	}
+	return limits, nil
+}
+
+// Merge copies the non-empty fields of o into s.
+func (s *Settings) Merge(o Settings) {
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&s.ConfigDir, o.ConfigDir)
+	set(&s.ConfigDB, o.ConfigDB)
+	set(&s.DumpRoot, o.DumpRoot)
+	set(&s.OutputRoot, o.OutputRoot)
+	set(&s.Template, o.Template)
+	set(&s.OutputFormat, o.OutputFormat)
+	set(&s.ReportDir, o.ReportDir)
+	set(&s.HistoryDB, o.HistoryDB)
+	for pattern, region := range o.Regions {
+		if s.Regions == nil {
+			s.Regions = make(map[string]string)
+		}
+		s.Regions[pattern] = region
+	}
+	if o.Workers > 0 {
+		s.Workers = o.Workers
+	}
+	if o.FileTimeout > 0 {
+		s.FileTimeout = o.FileTimeout
+	}
+	if o.QueryTimeout > 0 {
+		s.QueryTimeout = o.QueryTimeout
+	}
+	if o.FailThreshold > 0 {
+		s.FailThreshold = o.FailThreshold
+	}
+	for driver, n := range o.DriverLimits {
+		if s.DriverLimits == nil {
+			s.DriverLimits = make(map[string]int)
+		}
+		s.DriverLimits[strings.ToLower(driver)] = n
+	}
+
+	for key, paths := range o.Targets {
+		if s.Targets == nil {
+			s.Targets = make(map[string]TargetPaths)
+		}
+		current := s.Targets[key]
+		set(&current.DumpDir, paths.DumpDir)
+		set(&current.OutputDir, paths.OutputDir)
+		s.Targets[key] = current
+	}
+}
+
+// resolve makes the relative paths of s relative to dir.
+func (s *Settings) resolve(dir string) {
+	abs := func(p *string) {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	abs(&s.ConfigDir)
+	abs(&s.ConfigDB)
+	abs(&s.DumpRoot)
+	abs(&s.OutputRoot)
+	abs(&s.Template)
+	abs(&s.ReportDir)
+	abs(&s.HistoryDB)
+	for key, paths := range s.Targets {
+		abs(&paths.DumpDir)
+		abs(&paths.OutputDir)
+		s.Targets[key] = paths
+	}
+}
+
+// target returns the overrides of a vendor/technology pair.
+func (s Settings) target(vendor, tech string) TargetPaths {
+	for key, paths := range s.Targets {
+		if strings.EqualFold(key, vendor+"/"+tech) {
+			return paths
+		}
+	}
+	return TargetPaths{}
+}
+
+// DumpDir returns the directory holding the dumps of a vendor/technology.
+func (s Settings) DumpDir(vendor, tech string) string {
+	if dir := s.target(vendor, tech).DumpDir; dir != "" {
+		return dir
+	}
+	return filepath.Join(s.DumpRoot, strings.ToLower(vendor), strings.ToLower(tech))
+}
+
+// OutputDir returns the directory receiving the results of a
+// vendor/technology.
+func (s Settings) OutputDir(vendor, tech string) string {
+	if dir := s.target(vendor, tech).OutputDir; dir != "" {
+		return dir
+	}
+	return filepath.Join(s.OutputRoot, strings.ToLower(vendor))
+}
@@ -0,0 +1,33 @@
+// Package sqlquote quotes identifiers taken from config workbooks and dumps
+// before they are placed in SQL statements. Values are never spliced into
+// statements; they are always bound as parameters.
+package sqlquote
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Access returns name as a bracketed Jet/ACE identifier. Jet object names
+// cannot contain . ! ` [ ] or control characters and brackets have no
+// escape, so such names are rejected instead of being quoted.
+func Access(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("empty Access identifier")
+	}
+	if len(name) > 64 {
+		return "", fmt.Errorf("Access identifier %q is longer than 64 characters", name)
+	}
+	for _, r := range name {
+		if strings.ContainsRune(".!`[]", r) || unicode.IsControl(r) {
+			return "", fmt.Errorf("Access identifier %q contains %q", name, r)
+		}
+	}
+	return "[" + name + "]", nil
+}
+
+// SQLite returns name as a double-quoted SQLite identifier.
+func SQLite(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
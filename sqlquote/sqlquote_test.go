@@ -0,0 +1,82 @@
+package sqlquote
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"unicode"
+
+	_ "modernc.org/sqlite"
+)
+
+func FuzzAccess(f *testing.F) {
+	for _, seed := range []string{"GCELL", "A_EQM_EQM_APEQM_ALD_RETU", "ADD GCELL", "Cell [1]", "a.b", "x]; DROP TABLE y; --", "", " ", "\x00", strings.Repeat("x", 65)} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		quoted, err := Access(name)
+		if err != nil {
+			return
+		}
+		// Jet ends a bracketed identifier at the first ], so the name must
+		// come back whole from the statement it is placed in.
+		stmt := "SELECT * FROM " + quoted + " WHERE 1 = 0"
+		start := strings.Index(stmt, "[")
+		end := strings.Index(stmt, "]")
+		if start < 0 || end < start || stmt[start+1:end] != name || stmt[end+1:] != " WHERE 1 = 0" {
+			t.Fatalf("Access(%q) = %q does not delimit the name in %q", name, quoted, stmt)
+		}
+		if strings.TrimSpace(name) == "" || len(name) > 64 {
+			t.Fatalf("Access(%q) accepted a name Jet rejects", name)
+		}
+		for _, r := range name {
+			if strings.ContainsRune(".!`[]", r) || unicode.IsControl(r) {
+				t.Fatalf("Access(%q) accepted %q", name, r)
+			}
+		}
+	})
+}
+
+func FuzzSQLite(f *testing.F) {
+	for _, seed := range []string{"GCELL", "ADD GCELL", `Cell "1"`, `""`, "x\"; DROP TABLE t; --", "select", "", " ", "ñame", "a.b"} {
+		f.Add(seed)
+	}
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	f.Fuzz(func(t *testing.T, name string) {
+		// SQLite names are C strings and reserves the sqlite_ prefix.
+		if strings.ContainsRune(name, 0) || strings.HasPrefix(strings.ToLower(name), "sqlite_") {
+			t.Skip()
+		}
+		q := SQLite(name)
+		// The second column is derived from name so the two never collide.
+		create := "CREATE TABLE " + q + " (" + q + " TEXT, " + SQLite(name+"_2") + " TEXT)"
+		if _, err := db.Exec(create); err != nil {
+			t.Fatalf("%s: %v", create, err)
+		}
+		defer db.Exec("DROP TABLE " + q)
+		stmts := []string{
+			"INSERT INTO " + q + " (" + q + ") VALUES ('v')",
+			"CREATE INDEX IF NOT EXISTS " + SQLite(name+"_idx") + " ON " + q + " (" + q + ")",
+		}
+		for _, stmt := range stmts {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatalf("%s: %v", stmt, err)
+			}
+		}
+
+		var table string
+		if err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&table); err != nil {
+			t.Fatalf("table %q not found as named: %v", name, err)
+		}
+		var v string
+		if err := db.QueryRow("SELECT " + q + " FROM " + q).Scan(&v); err != nil || v != "v" {
+			t.Fatalf("SELECT %s FROM %s = %q, %v", q, q, v, err)
+		}
+	})
+}
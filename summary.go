@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"parameterCheck/rules"
+)
+
+// Exit codes of the check and interactive commands.
+const (
+	exitOK           = 0
+	exitNonCompliant = 1 // the NotMatched share is above the threshold
+	exitError        = 2 // a dump or table could not be checked
+)
+
+// dumpSummary is the outcome of checking one dump.
+type dumpSummary struct {
+	target string
+	path   string
+	// tables and rules count the tables checked and the rules they hold;
+	// flags counts the result rows written per flag.
+	tables int
+	rules  int
+	rows   int
+	flags  map[string]int
+	// failures lists the tables that could not be checked.
+	failures []string
+	// err is why the dump's results were discarded, if they were.
+	err     error
+	skipped bool
+	// unchanged is set for a dump whose results were kept because the
+	// manifest lists it with the same content and rules; the counts are
+	// those of its earlier check, or of the check feeding the report and
+	// history.
+	unchanged bool
+}
+
+func newDumpSummary(target, path string) *dumpSummary {
+	return &dumpSummary{target: target, path: path, flags: make(map[string]int)}
+}
+
+func (d *dumpSummary) status() string {
+	switch {
+	case d.skipped:
+		return "skipped"
+	case d.err != nil:
+		return "failed"
+	case len(d.failures) > 0:
+		return fmt.Sprintf("%d tables failed", len(d.failures))
+	case d.unchanged:
+		return "unchanged"
+	}
+	return "ok"
+}
+
+// runSummary collects the outcome of every dump of a run, and the failures
+// not tied to a dump.
+type runSummary struct {
+	mu       sync.Mutex
+	dumps    []*dumpSummary
+	failures []string
+}
+
+func (s *runSummary) add(d *dumpSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dumps = append(s.dumps, d)
+}
+
+// fail logs a failure not tied to a dump and records it.
+func (s *runSummary) fail(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	log.Print(msg)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, msg)
+}
+
+// counts returns the result rows per flag over the dumps whose results
+// were kept.
+func (s *runSummary) counts() map[string]int {
+	counts := make(map[string]int)
+	for _, d := range s.dumps {
+		if d.err != nil {
+			continue
+		}
+		for flag, n := range d.flags {
+			counts[flag] += n
+		}
+	}
+	return counts
+}
+
+// nonCompliance returns the NotMatched share of the checked rows in
+// percent, the complement of the report's compliance.
+func (s *runSummary) nonCompliance() float64 {
+	counts := s.counts()
+	checked := counts[rules.FlagMatch] + counts[rules.FlagNotMatched]
+	if checked == 0 {
+		return 0
+	}
+	return 100 * float64(counts[rules.FlagNotMatched]) / float64(checked)
+}
+
+// failed reports whether a dump or table could not be checked.
+func (s *runSummary) failed() bool {
+	if len(s.failures) > 0 {
+		return true
+	}
+	for _, d := range s.dumps {
+		if d.skipped || d.err != nil || len(d.failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// exitCode returns exitError when anything could not be checked, else
+// exitNonCompliant when the NotMatched share is above threshold percent.
+func (s *runSummary) exitCode(threshold float64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed() {
+		return exitError
+	}
+	if s.nonCompliance() > threshold {
+		return exitNonCompliant
+	}
+	return exitOK
+}
+
+// print writes a line per dump, the failures with their reasons and the
+// totals of the run.
+func (s *runSummary) print(w io.Writer, threshold float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sort.Slice(s.dumps, func(i, j int) bool {
+		if s.dumps[i].target != s.dumps[j].target {
+			return s.dumps[i].target < s.dumps[j].target
+		}
+		return s.dumps[i].path < s.dumps[j].path
+	})
+
+	fmt.Fprintln(w, "Run summary")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Target\tDump\tTables\tRules\tRows\tMatch\tNotMatched\tOther\tStatus")
+	for _, d := range s.dumps {
+		other := d.rows - d.flags[rules.FlagMatch] - d.flags[rules.FlagNotMatched]
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", d.target, filepath.Base(d.path),
+			d.tables, d.rules, d.rows, d.flags[rules.FlagMatch], d.flags[rules.FlagNotMatched], other, d.status())
+	}
+	tw.Flush()
+
+	var failures []string
+	for _, d := range s.dumps {
+		if d.err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", d.path, d.err))
+		}
+		for _, f := range d.failures {
+			failures = append(failures, fmt.Sprintf("%s: %s", d.path, f))
+		}
+	}
+	failures = append(failures, s.failures...)
+	if len(failures) > 0 {
+		fmt.Fprintln(w, "Failures")
+		for _, f := range failures {
+			fmt.Fprintln(w, "  "+f)
+		}
+	}
+
+	counts := s.counts()
+	failedDumps, unchanged := 0, 0
+	for _, d := range s.dumps {
+		switch {
+		case d.skipped || d.err != nil:
+			failedDumps++
+		case d.unchanged:
+			unchanged++
+		}
+	}
+	fmt.Fprintf(w, "%d dumps checked, %d unchanged, %d failed or skipped; Match %d, NotMatched %d; NotMatched share %.2f%% (threshold %.2f%%)\n",
+		len(s.dumps)-failedDumps-unchanged, unchanged, failedDumps, counts[rules.FlagMatch], counts[rules.FlagNotMatched], s.nonCompliance(), threshold)
+}